@@ -2,51 +2,263 @@ package main
 
 import (
 	"context"
-	"github.com/gin-gonic/gin"
+	"flag"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/fx"
-	"log"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/zap"
+	"io"
 	"os"
+	"strings"
 	"task-manager-backend/internal/app/api"
 	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/health"
+	"task-manager-backend/internal/app/logging"
+	"task-manager-backend/internal/app/metrics"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/queue"
 	"task-manager-backend/internal/app/repository"
 	"task-manager-backend/internal/app/repository/redis_repository"
 	"task-manager-backend/internal/app/service/auth"
 	"task-manager-backend/internal/app/service/mail"
+	"task-manager-backend/internal/app/service/notification"
+	"task-manager-backend/internal/app/service/profile"
+	"task-manager-backend/internal/app/service/realtime"
+	"task-manager-backend/internal/app/service/webhook"
+	"task-manager-backend/internal/app/service/workspace"
+	"task-manager-backend/internal/app/tracing"
+	"time"
 )
 
+const stopMargin = 10 * time.Second
+
 type combineAuthRepository struct {
 	*repository.PostgresRepository
 	*redis_repository.RedisRepository
 }
 
-func authStorage(repository *repository.PostgresRepository) auth.Repository {
-	rs, err := redis_repository.NewRedisRepo(config.Load().RedisAddr, config.Load().RedisPasswd, 0)
+type combineRealtimeRepository struct {
+	*repository.PostgresRepository
+	*redis_repository.RedisRepository
+}
+
+// redisStorage connects to Redis, retrying for a while if it is not up yet.
+// If it stays down the service starts anyway and the client reconnects once
+// it is back.
+func redisStorage(lifecycle fx.Lifecycle, cfg config.ServiceConfiguration, registerer prometheus.Registerer, logger *zap.Logger) *redis_repository.RedisRepository {
+	rs, err := redis_repository.NewRedisRepo(cfg.RedisAddr, cfg.RedisPasswd, 0, registerer)
 	if err != nil {
-		log.Printf("redis connect err: %v", err)
-		os.Exit(1)
+		health.WaitFor(logger, "redis", cfg.Health.StartupTimeout, rs.Ping)
 	}
+	lifecycle.Append(
+		fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				return rs.Close()
+			},
+		})
+	return rs
+}
+
+func authStorage(repository *repository.PostgresRepository, rs *redis_repository.RedisRepository) auth.Repository {
 	return combineAuthRepository{
 		repository, rs,
 	}
 }
 
+func realtimeStorage(repository *repository.PostgresRepository, rs *redis_repository.RedisRepository) realtime.Repository {
+	return combineRealtimeRepository{
+		repository, rs,
+	}
+}
+
+func profileStorage(repository *repository.PostgresRepository) profile.Repository {
+	return repository
+}
+
+func notificationStorage(repository *repository.PostgresRepository) notification.Repository {
+	return repository
+}
+
+func outboxStorage(repository *repository.PostgresRepository) events.OutboxRepository {
+	return repository
+}
+
+func queueStorage(repository *repository.PostgresRepository) queue.Repository {
+	return repository
+}
+
+func webhookStorage(repository *repository.PostgresRepository) webhook.Repository {
+	return repository
+}
+
+func workspaceStorage(repository *repository.PostgresRepository) workspace.Repository {
+	return repository
+}
+
+// healthChecks registers the dependencies checked by /readyz. The API
+// cant work without Postgres and Redis; mail is queued, so a mail outage
+// only degrades it.
+func healthChecks(checker *health.Checker, postgres *repository.PostgresRepository, redis *redis_repository.RedisRepository, transport mail.Transport) {
+	checker.Register("postgres", true, postgres.Ping)
+	checker.Register("redis", true, redis.Ping)
+	checker.Register("mail", false, func(ctx context.Context) error {
+		return mail.Ping(ctx, transport)
+	})
+}
+
+// configRequired reports whether the config file was named with -config.
+// Only then does it have to exist.
+func configRequired() bool {
+	required := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			required = true
+		}
+	})
+	return required
+}
+
+// previewMail writes a template rendered with the sample data to stdout:
+// the HTML version, or the subject and the text version for text. Nothing is
+// reloaded during a preview, so nothing is logged either.
+func previewMail(cfg config.ServiceConfiguration, configPath string, args []string) error {
+	name, locale, format := args[0], users.DefaultLocale, "html"
+	if len(args) > 1 {
+		locale = args[1]
+	}
+	if len(args) > 2 {
+		format = args[2]
+	}
+	if format != "html" && format != "text" {
+		return fmt.Errorf("unknown preview format %q, want html or text", format)
+	}
+
+	renderer, err := mail.NewRenderer(cfg, config.NewStore(cfg, configPath), zap.NewNop())
+	if err != nil {
+		return err
+	}
+	msg, err := renderer.Render(name, locale, "user@example.com", mail.Samples[name])
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	if format == "text" {
+		_, err = fmt.Fprintf(os.Stdout, "Subject: %s\n\n%s", msg.Subject, msg.Text)
+	} else {
+		_, err = io.WriteString(os.Stdout, msg.HTML)
+	}
+	return err
+}
+
 // main godoc
+// @description Ошибки описываются в формате Problem (application/problem+json).
+// @description v1 по умолчанию отвечает в прежнем формате Error со старыми статусами; Problem можно запросить заголовком Accept: application/problem+json
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name Authorization
 func main() {
+	configPath := flag.String("config", config.DefaultPath, "path to the YAML config file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-config file] [config print | mail preview <template> [locale] [html|text]]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	command := strings.Join(args, " ")
+	preview := len(args) >= 3 && len(args) <= 5 && args[0] == "mail" && args[1] == "preview"
+	if command != "" && command != "config print" && !preview {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*configPath, configRequired())
+	_, invalid := err.(config.ValidationError)
+
+	// An invalid configuration is still printed, since that is when it is
+	// most needed.
+	if command == "config print" && (err == nil || invalid) {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	// Previews only read the mail settings, so designers need no database
+	// or secrets for them.
+	if preview && (err == nil || invalid) {
+		if err := previewMail(cfg, *configPath, args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if command != "" {
+		return
+	}
+
 	fx.New(
+		fx.Supply(cfg, config.NewStore(cfg, *configPath)),
+		// Leaves the other hooks time to stop once requests have drained.
+		fx.StopTimeout(cfg.Api.ShutdownTimeout+stopMargin),
+		fx.WithLogger(func(logger *zap.Logger) fxevent.Logger {
+			return &fxevent.ZapLogger{Logger: logger}
+		}),
 		fx.Provide(
 			context.Background,
-			config.NewConfig,
+			logging.New,
+			api.NewRouter,
 			api.NewApi,
-			gin.Default,
+			health.NewChecker,
+			metrics.NewRegistry,
+			metrics.Registerer,
 			repository.NewPostgresRepository,
+			mail.NewTransport,
 			mail.NewSender,
+			mail.NewRenderer,
+			mail.NewMailer,
+			events.NewBus,
+			outboxStorage,
+			queueStorage,
+			queue.NewQueue,
+			redisStorage,
 			authStorage,
 			auth.NewManger,
+			auth.NewPasswordPolicy,
+			auth.NewHasher,
+			auth.NewRegistrationPolicy,
 			auth.NewService,
+			workspaceStorage,
+			workspace.NewService,
+			profileStorage,
+			profile.NewService,
+			notificationStorage,
+			notification.NewService,
+			realtimeStorage,
+			realtime.NewService,
+			webhookStorage,
+			webhook.NewService,
+		),
+		fx.Invoke(
+			tracing.Hook,
+			config.WatchHook,
+			healthChecks,
+			mail.RegisterJobs,
+			auth.RegisterMailHandlers,
+			notification.RegisterHandlers,
+			realtime.RegisterHandlers,
+			webhook.RegisterHandlers,
+			queue.Hook,
+			events.RelayHook,
+			api.StartHook,
+			auth.CleanupHook,
+			realtime.Hook,
+			webhook.Hook,
 		),
-		fx.Invoke(api.StartHook),
 	).Run()
 }
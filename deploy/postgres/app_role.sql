@@ -0,0 +1,5 @@
+-- Run by the postgres image when it initialises an empty data directory.
+-- Creates the role the API connects as for local development; the grants
+-- are made by the migrations. Row level security only applies to roles
+-- that are neither superusers nor BYPASSRLS.
+CREATE ROLE task_manager LOGIN PASSWORD 'devpass' NOSUPERUSER NOBYPASSRLS;
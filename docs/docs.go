@@ -1,4 +1,4 @@
-// Package docs GENERATED BY SWAG; DO NOT EDIT
+// Package docs GENERATED BY THE COMMAND ABOVE; DO NOT EDIT
 // This file was generated by swaggo/swag
 package docs
 
@@ -16,7 +16,7 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/confirm/{confirm_token}": {
+        "/v1/auth/confirm/{confirm_token}": {
             "get": {
                 "description": "Подтверждает регистрацию пользователя",
                 "tags": [
@@ -40,15 +40,21 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
                     }
                 }
             }
         },
-        "/auth/logout": {
+        "/v1/auth/logout": {
             "post": {
                 "description": "Инвалидирует сессию для устройства, с которого выполняется выход",
                 "consumes": [
@@ -74,17 +80,23 @@ const docTemplate = `{
                         "description": "OK"
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
                     }
                 }
             }
         },
-        "/auth/new_password": {
+        "/v1/auth/new_password": {
             "post": {
-                "description": "Меняет пароль пользователя на новый",
+                "description": "Меняет пароль пользователя на новый\nУстарело: используйте /v2/auth/new_password",
                 "consumes": [
                     "application/json"
                 ],
@@ -92,6 +104,7 @@ const docTemplate = `{
                     "auth"
                 ],
                 "summary": "Восстановление пароля",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "Входные параметры",
@@ -123,14 +136,17 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
                     }
                 }
             }
         },
-        "/auth/refresh": {
+        "/v1/auth/refresh": {
             "post": {
-                "description": "Обновляет JWT по refresh токену\nДля того что бы обновить токен надо быть\nаунтифицированным",
+                "description": "Обновляет JWT по refresh токену\nДля того что бы обновить токен надо быть\nаунтифицированным\nВ v1 неизвестный или истёкший токен тоже даёт 401, раньше был 500",
                 "consumes": [
                     "application/json"
                 ],
@@ -160,15 +176,73 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/auth/resend_confirmation": {
+            "post": {
+                "description": "Повторно отправляет письмо со ссылкой для подтверждения регистрации\nКоличество повторных отправок ограничено\nДля незарегистрированного или уже подтверждённого email ответ тот же, но письмо не отправляется",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Повторная отправка письма подтверждения",
+                "parameters": [
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.ConfirmationEmail"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
                     }
                 }
             }
         },
-        "/auth/restore_password": {
+        "/v1/auth/restore_password": {
             "post": {
                 "description": "Отправляет ссылку на страницу с восстановлением пароля",
                 "consumes": [
@@ -196,24 +270,33 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
                     }
                 }
             }
         },
-        "/auth/signin": {
+        "/v1/auth/signin": {
             "post": {
-                "description": "Вход в систему по логину и хешу-пароля",
+                "description": "Вход в систему по логину и хешу-пароля\nУстарело: используйте /v2/auth/signin",
                 "consumes": [
                     "application/json"
                 ],
@@ -224,6 +307,7 @@ const docTemplate = `{
                     "auth"
                 ],
                 "summary": "Вход в систему",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "Входные параметры",
@@ -255,14 +339,17 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
                     }
                 }
             }
         },
-        "/auth/signup": {
+        "/v1/auth/signup": {
             "post": {
-                "description": "Прямая регистрация нового пользователя в системе",
+                "description": "Прямая регистрация нового пользователя в системе\nУстарело: используйте /v2/auth/signup",
                 "consumes": [
                     "application/json"
                 ],
@@ -273,6 +360,7 @@ const docTemplate = `{
                     "auth"
                 ],
                 "summary": "Регистрация",
+                "deprecated": true,
                 "parameters": [
                     {
                         "description": "Входные параметры",
@@ -280,7 +368,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Auth"
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.SignUpAuth"
                         }
                     }
                 ],
@@ -301,99 +389,2096 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "internal_app_api.Auth": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "pwd_hash": {
-                    "type": "string"
-                }
-            }
-        },
-        "internal_app_api.ChangePassword": {
-            "type": "object",
-            "properties": {
-                "new_password": {
-                    "type": "string"
-                },
-                "restore_refresh": {
-                    "type": "string"
-                }
-            }
-        },
-        "internal_app_api.Error": {
-            "type": "object",
-            "properties": {
-                "err": {
-                    "type": "string"
-                }
-            }
         },
-        "internal_app_api.Refresh": {
-            "type": "object",
-            "properties": {
-                "refresh_token": {
-                    "type": "string"
+        "/v1/invites": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отправляет приглашение на регистрацию\nБез рабочего пространства доступно администраторам, с ним - его владельцу\nи администраторам. Приглашённый сразу становится участником с указанной ролью",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Приглашение пользователя",
+                "parameters": [
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Invite"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
                 }
             }
         },
-        "internal_app_api.RestorePasswordEmail": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string"
+        "/v1/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает фоновые задачи с указанным статусом, по умолчанию — упавшие (dead) после всех попыток\nПолезная нагрузка задач не возвращается: в ней бывают токены из писем\nДоступно только администраторам",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Список фоновых задач",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending, running или dead",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество, по умолчанию 20, не больше 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Jobs"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
                 }
             }
         },
-        "internal_app_api.Tokens": {
-            "type": "object",
-            "properties": {
-                "tokens": {
-                    "$ref": "#/definitions/users.Session"
+        "/v1/jobs/{job_id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает упавшую задачу в очередь с новым набором попыток\nЗадачу с ключом нельзя повторить, пока в очереди ждёт другая с тем же ключом (job_key_pending)\nДоступно только администраторам",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Повтор упавшей задачи",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор задачи",
+                        "name": "job_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
                 }
             }
         },
-        "task-manager-backend_internal_app_api.Auth": {
-            "type": "object",
+        "/v1/mail/preview/{template}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отрисовывает шаблон письма на тестовых данных\nБез format возвращает тему и обе версии письма, с format=html или format=text — только одну версию как есть\nДоступно только администраторам",
+                "produces": [
+                    "application/json",
+                    "text/html",
+                    "text/plain"
+                ],
+                "tags": [
+                    "mail"
+                ],
+                "summary": "Предпросмотр письма",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя шаблона",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык, по умолчанию ru",
+                        "name": "locale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "html или text",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/mail.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/mail/templates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Доступно только администраторам",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mail"
+                ],
+                "summary": "Список шаблонов писем",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.MailTemplates"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает профиль пользователя, выполнившего запрос",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Профиль текущего пользователя",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/users.Profile"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Изменяет переданные поля профиля текущего пользователя\nЧасовой пояс задаётся именем из базы IANA, язык - тегом BCP 47",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Изменение профиля",
+                "parameters": [
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/users.ProfileUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/users.Profile"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/me/avatar": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Принимает изображение JPEG, PNG или GIF размером до 5 МБ\nИзображение обрезается до квадрата и сохраняется в нескольких размерах",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Загрузка аватара",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Изображение",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/users.Profile"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает уведомления пользователя, начиная с новых, и число непрочитанных",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Список уведомлений",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Только непрочитанные",
+                        "name": "unread_only",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество, по умолчанию 20, не больше 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Notifications"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/notifications/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает канал доставки (in_app, email, none) для каждого типа событий",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Настройки уведомлений",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.NotificationPreferences"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Задаёт канал доставки для переданных типов событий",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Изменение настроек уведомлений",
+                "parameters": [
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.NotificationPreferences"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.NotificationPreferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/notifications/read_all": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Отметить все уведомления прочитанными",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/notifications/unread_count": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Число непрочитанных уведомлений",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.UnreadCount"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/notifications/{notification_id}/read": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Отметить уведомление прочитанным",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID уведомления",
+                        "name": "notification_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/stream": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events с событиями задач, комментариев и досок\nво всех рабочих пространствах пользователя.\nБраузерный EventSource не умеет передавать заголовки, поэтому токен\nможно передать параметром access_token. После переподключения\nпропущенные события досылаются начиная с Last-Event-ID.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "realtime"
+                ],
+                "summary": "Поток событий",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT, если нельзя передать заголовок Authorization",
+                        "name": "access_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "То же, что заголовок Last-Event-ID",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/workspace/members": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает участников активного рабочего пространства",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Участники рабочего пространства",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Активное рабочее пространство",
+                        "name": "X-Workspace-ID",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Members"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Добавляет пользователя в активное рабочее пространство\nДоступно владельцу и администраторам пространства",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Добавление участника",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Активное рабочее пространство",
+                        "name": "X-Workspace-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.AddMember"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/workspaces.Member"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/workspace/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает вебхуки активного рабочего пространства без секретов",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Список вебхуков",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Активное рабочее пространство",
+                        "name": "X-Workspace-ID",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Webhooks"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Подписывает URL на события активного рабочего пространства: task.created, task.updated, comment.created, member.added\nСекрет возвращается только в ответе на создание. Каждый запрос подписан заголовком X-Webhook-Signature: sha256=HMAC-SHA256(секрет, \"\u003cX-Webhook-Timestamp\u003e.\u003cтело\u003e\")\nНеудачные доставки повторяются с экспоненциальной задержкой, после нескольких проваленных доставок подряд вебхук отключается\nДоступно владельцу и администраторам пространства",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Создание вебхука",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Активное рабочее пространство",
+                        "name": "X-Workspace-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.CreateWebhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/workspace/webhooks/{webhook_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Удаление вебхука",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Активное рабочее пространство",
+                        "name": "X-Workspace-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор вебхука",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/workspace/webhooks/{webhook_id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает доставки вебхука, начиная с новых, со статусом ответа и последней ошибкой",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Журнал доставок вебхука",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Активное рабочее пространство",
+                        "name": "X-Workspace-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор вебхука",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество, по умолчанию 20, не больше 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Deliveries"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/workspace/webhooks/{webhook_id}/deliveries/{delivery_id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Ставит в очередь новую доставку с тем же содержимым",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Повторная отправка доставки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Активное рабочее пространство",
+                        "name": "X-Workspace-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор вебхука",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор доставки",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.Delivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/workspace/webhooks/{webhook_id}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Снова включает вебхук, отключённый из-за ошибок доставки, и сбрасывает счётчик ошибок",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Включение вебхука",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Активное рабочее пространство",
+                        "name": "X-Workspace-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор вебхука",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/workspaces": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает рабочие пространства, в которых состоит пользователь",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Список рабочих пространств",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Workspaces"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создаёт рабочее пространство, создатель становится его владельцем",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workspaces"
+                ],
+                "summary": "Создание рабочего пространства",
+                "parameters": [
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.CreateWorkspace"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/workspaces.Workspace"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/auth/confirm/{confirm_token}": {
+            "get": {
+                "description": "Подтверждает регистрацию пользователя",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Подтверждение регистрации",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "token конфирмации",
+                        "name": "confirm_token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Tokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/auth/logout": {
+            "post": {
+                "description": "Инвалидирует сессию для устройства, с которого выполняется выход",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Выход с аккаунта",
+                "parameters": [
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Refresh"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/auth/new_password": {
+            "post": {
+                "description": "Меняет пароль пользователя на новый\nПароль передаётся в открытом виде, хеширование выполняет сервер",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Восстановление пароля",
+                "parameters": [
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.ChangePassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Tokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/auth/refresh": {
+            "post": {
+                "description": "Обновляет JWT по refresh токену\nДля того что бы обновить токен надо быть\nаунтифицированным\nВ v1 неизвестный или истёкший токен тоже даёт 401, раньше был 500",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Обновить JWT",
+                "parameters": [
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Refresh"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Tokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/auth/resend_confirmation": {
+            "post": {
+                "description": "Повторно отправляет письмо со ссылкой для подтверждения регистрации\nКоличество повторных отправок ограничено\nДля незарегистрированного или уже подтверждённого email ответ тот же, но письмо не отправляется",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Повторная отправка письма подтверждения",
+                "parameters": [
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.ConfirmationEmail"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/auth/restore_password": {
+            "post": {
+                "description": "Отправляет ссылку на страницу с восстановлением пароля",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Отправка ссылки для восстановления пароля",
+                "parameters": [
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.RestorePasswordEmail"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/auth/signin": {
+            "post": {
+                "description": "Вход в систему по email и паролю\nПароль передаётся в открытом виде, хеширование выполняет сервер\nДля аккаунтов, зарегистрированных через v1, до первого входа через v2\nнужно также передать pwd_hash, который клиент отправлял в v1",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Вход в систему",
+                "parameters": [
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Credentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Tokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/auth/signup": {
+            "post": {
+                "description": "Прямая регистрация нового пользователя в системе\nПароль передаётся в открытом виде, хеширование выполняет сервер",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Регистрация",
+                "parameters": [
+                    {
+                        "description": "Входные параметры",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.SignUpCredentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/task-manager-backend_internal_app_api.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "task-manager-backend_internal_app_api.AddMember": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.Auth": {
+            "type": "object",
+            "required": [
+                "email",
+                "pwd_hash"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "pwd_hash": {
+                    "type": "string"
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.ChangePassword": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "restore_refresh"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "restore_refresh": {
+                    "type": "string"
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.ConfirmationEmail": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.CreateWebhook": {
+            "type": "object",
+            "required": [
+                "event_types",
+                "url"
+            ],
+            "properties": {
+                "event_types": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.CreateWorkspace": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.Credentials": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "pwd_hash": {
+                    "type": "string"
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.Deliveries": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhooks.Delivery"
+                    }
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.Error": {
+            "type": "object",
+            "properties": {
+                "err": {
+                    "type": "string"
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.Invite": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
             "properties": {
                 "email": {
                     "type": "string"
                 },
-                "pwd_hash": {
+                "role": {
                     "type": "string"
+                },
+                "workspace_id": {
+                    "type": "integer"
                 }
             }
         },
-        "task-manager-backend_internal_app_api.ChangePassword": {
+        "task-manager-backend_internal_app_api.Jobs": {
             "type": "object",
             "properties": {
-                "new_password": {
-                    "type": "string"
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jobs.Job"
+                    }
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.MailTemplates": {
+            "type": "object",
+            "properties": {
+                "templates": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.Members": {
+            "type": "object",
+            "properties": {
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/workspaces.Member"
+                    }
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.NotificationPreferences": {
+            "type": "object",
+            "required": [
+                "preferences"
+            ],
+            "properties": {
+                "preferences": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/notifications.Preference"
+                    }
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.Notifications": {
+            "type": "object",
+            "properties": {
+                "notifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/notifications.Notification"
+                    }
                 },
-                "restore_refresh": {
-                    "type": "string"
+                "unread": {
+                    "type": "integer"
                 }
             }
         },
-        "task-manager-backend_internal_app_api.Error": {
+        "task-manager-backend_internal_app_api.Problem": {
             "type": "object",
             "properties": {
-                "err": {
+                "code": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/errs.FieldError"
+                    }
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
                     "type": "string"
                 }
             }
         },
         "task-manager-backend_internal_app_api.Refresh": {
             "type": "object",
+            "required": [
+                "refresh_token"
+            ],
             "properties": {
                 "refresh_token": {
                     "type": "string"
@@ -402,9 +2487,48 @@ const docTemplate = `{
         },
         "task-manager-backend_internal_app_api.RestorePasswordEmail": {
             "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.SignUpAuth": {
+            "type": "object",
+            "required": [
+                "email",
+                "pwd_hash"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "invite_token": {
+                    "type": "string"
+                },
+                "pwd_hash": {
+                    "type": "string"
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.SignUpCredentials": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
             "properties": {
                 "email": {
                     "type": "string"
+                },
+                "invite_token": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
                 }
             }
         },
@@ -416,6 +2540,202 @@ const docTemplate = `{
                 }
             }
         },
+        "task-manager-backend_internal_app_api.UnreadCount": {
+            "type": "object",
+            "properties": {
+                "unread": {
+                    "type": "integer"
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.Webhooks": {
+            "type": "object",
+            "properties": {
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhooks.Webhook"
+                    }
+                }
+            }
+        },
+        "task-manager-backend_internal_app_api.Workspaces": {
+            "type": "object",
+            "properties": {
+                "workspaces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/workspaces.Workspace"
+                    }
+                }
+            }
+        },
+        "errs.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "params": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "jobs.Job": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "mail.Message": {
+            "type": "object",
+            "properties": {
+                "html": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "notifications.Notification": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "$ref": "#/definitions/notifications.Payload"
+                },
+                "read": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "notifications.Payload": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "notifications.Preference": {
+            "type": "object",
+            "required": [
+                "event_type"
+            ],
+            "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "users.Profile": {
+            "type": "object",
+            "properties": {
+                "avatars": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_login": {
+                    "type": "string"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "reg_date": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
+                }
+            }
+        },
+        "users.ProfileUpdate": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "display_name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
+                }
+            }
+        },
         "users.Session": {
             "type": "object",
             "properties": {
@@ -426,6 +2746,107 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "webhooks.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "response_status": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhooks.Webhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "failures": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "workspace_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "workspaces.Member": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "workspace_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "workspaces.Workspace": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "integer"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -444,7 +2865,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "",
 	Schemes:          []string{},
 	Title:            "",
-	Description:      "",
+	Description:      "Ошибки описываются в формате Problem (application/problem+json).\nv1 по умолчанию отвечает в прежнем формате Error со старыми статусами; Problem можно запросить заголовком Accept: application/problem+json",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 }
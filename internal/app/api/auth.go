@@ -3,8 +3,11 @@ package api
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 	"net/http"
 	"strings"
+	"task-manager-backend/internal/app/errs"
+	"task-manager-backend/internal/app/logging"
 	"task-manager-backend/internal/app/models/users"
 	"task-manager-backend/internal/app/service/auth"
 )
@@ -15,13 +18,16 @@ const (
 	userIDContextKey = "uid"
 )
 
-type Error struct {
-	Err string `json:"err"`
+type Auth struct {
+	Email   users.Email `json:"email" binding:"required,email"`
+	PwdHash string      `json:"pwd_hash" binding:"required"`
 }
 
-type Auth struct {
-	Email   users.Email `json:"email"`
-	PwdHash string      `json:"pwd_hash"`
+// SignUpAuth is Auth with the invite a new account may be registered with.
+type SignUpAuth struct {
+	Email       users.Email `json:"email" binding:"required,email"`
+	PwdHash     string      `json:"pwd_hash" binding:"required"`
+	InviteToken string      `json:"invite_token,omitempty"`
 }
 
 type Tokens struct {
@@ -32,33 +38,34 @@ type Tokens struct {
 // @Summary Регистрация
 // @Schemes
 // @Description Прямая регистрация нового пользователя в системе
+// @Description Устарело: используйте /v2/auth/signup
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param data body Auth true "Входные параметры"
+// @Param data body SignUpAuth true "Входные параметры"
 // @Success 200
 // @Failure 400 {object} Error
 // @Failure 403 {object} Error
-// @Failure 500
-// @Router /auth/signup [post]
+// @Failure 500 {object} Error
+// @Deprecated
+// @Router /v1/auth/signup [post]
 func (api *Api) SignUp(ctx *gin.Context) {
-	var req Auth
-	if err := ctx.BindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, Error{Err: auth.InvalidData.Error()})
+	var req SignUpAuth
+	if err := api.bindJSON(ctx, &req); err != nil {
+		fail(ctx, err)
 		return
 	}
 
-	err := api.auth.Register(ctx, string(req.PwdHash), req.Email)
-	if err == auth.InvalidData {
-		ctx.JSON(http.StatusBadRequest, Error{Err: auth.InvalidData.Error()})
-		return
-	}
-	if err == auth.UserAlreadyExist {
-		ctx.JSON(http.StatusForbidden, Error{Err: auth.UserAlreadyExist.Error()})
-		return
-	}
+	api.register(ctx, "pwd_hash", req.PwdHash, true, req.Email, req.InviteToken)
+}
+
+// register signs the user up. passwordField names the request field with
+// the password, which differs between API versions, and legacy tells
+// whether it is the client-side pwd_hash of v1.
+func (api *Api) register(ctx *gin.Context, passwordField, password string, legacy bool, email users.Email, inviteToken string) {
+	err := api.auth.Register(ctx, password, legacy, email, inviteToken)
 	if err != nil {
-		ctx.AbortWithStatus(http.StatusInternalServerError)
+		fail(ctx, passwordError(passwordField, err))
 		return
 	}
 
@@ -73,18 +80,19 @@ func (api *Api) SignUp(ctx *gin.Context) {
 // @Accept json
 // @Param data body Refresh true "Входные параметры"
 // @Success 200
-// @Failure 400
-// @Failure 500
-// @Router /auth/logout [post]
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /v1/auth/logout [post]
+// @Router /v2/auth/logout [post]
 func (api *Api) Logout(ctx *gin.Context) {
 	var refresh Refresh
-	if err := ctx.BindJSON(&refresh); err != nil {
-		ctx.AbortWithStatus(http.StatusBadRequest)
+	if err := api.bindJSON(ctx, &refresh); err != nil {
+		fail(ctx, err)
 		return
 	}
 
 	if err := api.auth.Logout(ctx, refresh.RefreshToken); err != nil {
-		ctx.AbortWithStatus(http.StatusInternalServerError)
+		fail(ctx, err)
 		return
 	}
 
@@ -95,6 +103,7 @@ func (api *Api) Logout(ctx *gin.Context) {
 // @Summary Вход в систему
 // @Schemes
 // @Description Вход в систему по логину и хешу-пароля
+// @Description Устарело: используйте /v2/auth/signin
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -102,30 +111,23 @@ func (api *Api) Logout(ctx *gin.Context) {
 // @Success 200 {object} Tokens
 // @Failure 400 {object} Error
 // @Failure 403 {object} Error
-// @Failure 500
-// @Router /auth/signin [post]
+// @Failure 500 {object} Error
+// @Deprecated
+// @Router /v1/auth/signin [post]
 func (api *Api) SignIn(ctx *gin.Context) {
 	var req Auth
-	if err := ctx.BindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, Error{Err: auth.InvalidData.Error()})
+	if err := api.bindJSON(ctx, &req); err != nil {
+		fail(ctx, err)
 		return
 	}
 
-	session, err := api.auth.Auth(ctx, string(req.PwdHash), req.Email)
-	if err == auth.InvalidData {
-		ctx.JSON(http.StatusBadRequest, Error{Err: auth.InvalidData.Error()})
-		return
-	}
-	if err == auth.NonConfirmed {
-		ctx.JSON(http.StatusForbidden, Error{Err: auth.NonConfirmed.Error()})
-		return
-	}
-	if err == auth.IncorrectCreds {
-		ctx.JSON(http.StatusForbidden, Error{Err: auth.IncorrectCreds.Error()})
-		return
-	}
+	session, err := api.auth.Auth(ctx, req.PwdHash, req.Email)
+	sendSession(ctx, session, err)
+}
+
+func sendSession(ctx *gin.Context, session users.Session, err error) {
 	if err != nil {
-		ctx.AbortWithStatus(http.StatusInternalServerError)
+		fail(ctx, err)
 		return
 	}
 
@@ -133,7 +135,7 @@ func (api *Api) SignIn(ctx *gin.Context) {
 }
 
 type Refresh struct {
-	RefreshToken string `json:"refresh_token"`
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 // Refresh godoc
@@ -142,24 +144,27 @@ type Refresh struct {
 // @Description Обновляет JWT по refresh токену
 // @Description Для того что бы обновить токен надо быть
 // @Description аунтифицированным
+// @Description В v1 неизвестный или истёкший токен тоже даёт 401, раньше был 500
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param data body Refresh true "Входные параметры"
 // @Success 200 {object} Tokens
-// @Failure 400
-// @Failure 500
-// @Router /auth/refresh [post]
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /v1/auth/refresh [post]
+// @Router /v2/auth/refresh [post]
 func (api *Api) Refresh(ctx *gin.Context) {
 	var token Refresh
-	if err := ctx.BindJSON(&token); err != nil {
-		ctx.AbortWithStatus(http.StatusBadRequest)
+	if err := api.bindJSON(ctx, &token); err != nil {
+		fail(ctx, err)
 		return
 	}
 
 	session, err := api.auth.RefreshToken(ctx, token.RefreshToken)
 	if err != nil {
-		ctx.AbortWithStatus(http.StatusInternalServerError)
+		fail(ctx, err)
 		return
 	}
 
@@ -170,17 +175,40 @@ func (api *Api) AuthMW() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		token, err := extractAuthToken(ctx)
 		if err != nil {
-			ctx.AbortWithStatus(http.StatusUnauthorized)
+			fail(ctx, auth.Unauthenticated)
 			return
 		}
 
 		userID, err := api.auth.UnmarshalToken(token)
 		if err != nil {
-			ctx.AbortWithStatus(http.StatusUnauthorized)
+			fail(ctx, auth.Unauthenticated)
 			return
 		}
 
 		putUserIDtoContext(ctx, userID)
+		logger := logging.FromContext(ctx, api.logger).With(zap.Uint64("user_id", uint64(userID)))
+		ctx.Request = ctx.Request.WithContext(logging.WithContext(ctx.Request.Context(), logger))
+	}
+}
+
+// AdminMW lets only admins through. It must run after AuthMW.
+func (api *Api) AdminMW() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := popUserIDfromContext(ctx)
+		if err != nil {
+			fail(ctx, err)
+			return
+		}
+
+		admin, err := api.auth.IsAdmin(ctx, userID)
+		if err != nil {
+			fail(ctx, err)
+			return
+		}
+		if !admin {
+			fail(ctx, auth.NotAllowed)
+			return
+		}
 	}
 }
 
@@ -191,7 +219,7 @@ func putUserIDtoContext(ctx *gin.Context, userID users.ID) {
 func popUserIDfromContext(ctx *gin.Context) (users.ID, error) {
 	userID, ok := ctx.Get(userIDContextKey)
 	if !ok {
-		return users.ID(0), errors.New("no user id in ctx")
+		return users.ID(0), auth.Unauthenticated
 	}
 
 	return userID.(users.ID), nil
@@ -225,32 +253,64 @@ type Confirmation struct {
 // @Description Подтверждает регистрацию пользователя
 // @Tags auth
 // @Success 200 {object} Tokens
-// @Failure 400
-// @Failure 500
+// @Failure 400 {object} Problem
+// @Failure 500 {object} Problem
 // @Param confirm_token path string true "token конфирмации"
-// @Router /auth/confirm/{confirm_token} [get]
+// @Router /v1/auth/confirm/{confirm_token} [get]
+// @Router /v2/auth/confirm/{confirm_token} [get]
 func (api *Api) Confirmation(ctx *gin.Context) {
 	var refresh Confirmation
-	if err := ctx.ShouldBindUri(&refresh); err != nil {
-		ctx.AbortWithStatus(http.StatusBadRequest)
+	if err := bindURI(ctx, &refresh); err != nil {
+		fail(ctx, err)
 		return
 	}
 
 	session, err := api.auth.ConfirmationUser(ctx, refresh.Token)
-	if err == auth.InvalidRefresh {
-		ctx.AbortWithStatus(http.StatusBadRequest)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Tokens{Session: session})
+}
+
+type ConfirmationEmail struct {
+	Email users.Email `json:"email" binding:"required,email"`
+}
+
+// ResendConfirmation godoc
+// @Summary Повторная отправка письма подтверждения
+// @Schemes
+// @Description Повторно отправляет письмо со ссылкой для подтверждения регистрации
+// @Description Количество повторных отправок ограничено
+// @Description Для незарегистрированного или уже подтверждённого email ответ тот же, но письмо не отправляется
+// @Tags auth
+// @Accept json
+// @Param data body ConfirmationEmail true "Входные параметры"
+// @Success 200
+// @Failure 400 {object} Problem
+// @Failure 429 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /v1/auth/resend_confirmation [post]
+// @Router /v2/auth/resend_confirmation [post]
+func (api *Api) ResendConfirmation(ctx *gin.Context) {
+	var req ConfirmationEmail
+	if err := api.bindJSON(ctx, &req); err != nil {
+		fail(ctx, err)
 		return
 	}
+
+	err := api.auth.ResendConfirmation(ctx, req.Email)
 	if err != nil {
-		ctx.AbortWithStatus(http.StatusInternalServerError)
+		fail(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, Tokens{Session: session})
+	ctx.AbortWithStatus(http.StatusOK)
 }
 
 type RestorePasswordEmail struct {
-	Email users.Email `json:"email"`
+	Email users.Email `json:"email" binding:"required,email"`
 }
 
 // RestorePassword godoc
@@ -261,27 +321,22 @@ type RestorePasswordEmail struct {
 // @Accept json
 // @Param data body RestorePasswordEmail true "Входные параметры"
 // @Success 200
-// @Failure 400 {object} Error
-// @Failure 404 {object} Error
-// @Failure 500
-// @Router /auth/restore_password [post]
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 429 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /v1/auth/restore_password [post]
+// @Router /v2/auth/restore_password [post]
 func (api *Api) RestorePassword(ctx *gin.Context) {
 	var restoreEmail RestorePasswordEmail
-	if err := ctx.BindJSON(&restoreEmail); err != nil {
-		ctx.JSON(http.StatusBadRequest, Error{Err: auth.InvalidData.Error()})
+	if err := api.bindJSON(ctx, &restoreEmail); err != nil {
+		fail(ctx, err)
 		return
 	}
 
 	err := api.auth.SendRestorePasswordMail(ctx, restoreEmail.Email)
-	if err == auth.InvalidData {
-		ctx.JSON(http.StatusBadRequest, Error{Err: auth.InvalidData.Error()})
-		return
-	}
-	if err == auth.NotFoundEmail {
-		ctx.JSON(http.StatusNotFound, Error{Err: auth.NotFoundEmail.Error()})
-		return
-	} else if err != nil {
-		ctx.AbortWithStatus(http.StatusInternalServerError)
+	if err != nil {
+		fail(ctx, err)
 		return
 	}
 
@@ -289,46 +344,46 @@ func (api *Api) RestorePassword(ctx *gin.Context) {
 }
 
 type ChangePassword struct {
-	RestoreRefresh string `json:"restore_refresh"`
-	NewPassword    string `json:"new_password"`
+	RestoreRefresh string `json:"restore_refresh" binding:"required"`
+	NewPassword    string `json:"new_password" binding:"required"`
 }
 
 // NewPassword godoc
 // @Summary Восстановление пароля
 // @Schemes
 // @Description Меняет пароль пользователя на новый
+// @Description Устарело: используйте /v2/auth/new_password
 // @Tags auth
 // @Accept json
 // @Param data body ChangePassword true "Входные параметры"
 // @Success 200 {object} Tokens
 // @Failure 400 {object} Error
 // @Failure 404 {object} Error
-// @Failure 500
-// @Router /auth/new_password [post]
+// @Failure 500 {object} Error
+// @Deprecated
+// @Router /v1/auth/new_password [post]
 func (api *Api) NewPassword(ctx *gin.Context) {
 	var changePassword ChangePassword
-	if err := ctx.BindJSON(&changePassword); err != nil {
-		ctx.JSON(http.StatusBadRequest, Error{Err: auth.InvalidData.Error()})
+	if err := api.bindJSON(ctx, &changePassword); err != nil {
+		fail(ctx, err)
 		return
 	}
 
-	session, err := api.auth.ChangePassword(ctx, changePassword.RestoreRefresh, changePassword.NewPassword)
-	if err == auth.InvalidData {
-		ctx.JSON(http.StatusBadRequest, Error{Err: auth.InvalidData.Error()})
-		return
-	}
-	if err == auth.InvalidRefresh {
-		ctx.JSON(http.StatusBadRequest, Error{Err: auth.InvalidRefresh.Error()})
-		return
-	}
-	if err == auth.SamePassword {
-		ctx.JSON(http.StatusBadRequest, Error{Err: auth.SamePassword.Error()})
-		return
-	}
+	session, err := api.auth.ChangePassword(ctx, changePassword.RestoreRefresh, changePassword.NewPassword, true)
 	if err != nil {
-		ctx.AbortWithStatus(http.StatusInternalServerError)
+		fail(ctx, passwordError("new_password", err))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, Tokens{Session: session})
 }
+
+// passwordError reports a password the policy rejected as a problem with
+// the request field carrying it.
+func passwordError(field string, err error) error {
+	var passErr *auth.PasswordError
+	if errors.As(err, &passErr) {
+		return errs.Validation(errs.Field(field, passErr))
+	}
+	return err
+}
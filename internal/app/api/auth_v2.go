@@ -0,0 +1,117 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"task-manager-backend/internal/app/models/users"
+)
+
+// Credentials carries the plaintext password, which must only be sent over
+// TLS. Hashing is done by the server alone. Accounts registered through v1
+// also need the pwd_hash their client sent there, until the first sign-in
+// through v2 has upgraded them.
+type Credentials struct {
+	Email    users.Email `json:"email" binding:"required,email"`
+	Password string      `json:"password" binding:"required"`
+	PwdHash  string      `json:"pwd_hash,omitempty"`
+}
+
+// SignUpCredentials are the credentials of a new account, which has no v1
+// pwd_hash, with the invite it may be registered with.
+type SignUpCredentials struct {
+	Email       users.Email `json:"email" binding:"required,email"`
+	Password    string      `json:"password" binding:"required"`
+	InviteToken string      `json:"invite_token,omitempty"`
+}
+
+// SignUpV2 godoc
+// @Summary Регистрация
+// @Schemes
+// @Description Прямая регистрация нового пользователя в системе
+// @Description Пароль передаётся в открытом виде, хеширование выполняет сервер
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param data body SignUpCredentials true "Входные параметры"
+// @Success 200
+// @Failure 400 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /v2/auth/signup [post]
+func (api *Api) SignUpV2(ctx *gin.Context) {
+	var req SignUpCredentials
+	if err := api.bindJSON(ctx, &req); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	api.register(ctx, "password", req.Password, false, req.Email, req.InviteToken)
+}
+
+// SignInV2 godoc
+// @Summary Вход в систему
+// @Schemes
+// @Description Вход в систему по email и паролю
+// @Description Пароль передаётся в открытом виде, хеширование выполняет сервер
+// @Description Для аккаунтов, зарегистрированных через v1, до первого входа через v2
+// @Description нужно также передать pwd_hash, который клиент отправлял в v1
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param data body Credentials true "Входные параметры"
+// @Success 200 {object} Tokens
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /v2/auth/signin [post]
+func (api *Api) SignInV2(ctx *gin.Context) {
+	var req Credentials
+	if err := api.bindJSON(ctx, &req); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	session, err := api.auth.AuthV2(ctx, req.Password, req.PwdHash, req.Email)
+	sendSession(ctx, session, err)
+}
+
+// NewPasswordV2 godoc
+// @Summary Восстановление пароля
+// @Schemes
+// @Description Меняет пароль пользователя на новый
+// @Description Пароль передаётся в открытом виде, хеширование выполняет сервер
+// @Tags auth
+// @Accept json
+// @Param data body ChangePassword true "Входные параметры"
+// @Success 200 {object} Tokens
+// @Failure 400 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /v2/auth/new_password [post]
+func (api *Api) NewPasswordV2(ctx *gin.Context) {
+	var changePassword ChangePassword
+	if err := api.bindJSON(ctx, &changePassword); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	session, err := api.auth.ChangePassword(ctx, changePassword.RestoreRefresh, changePassword.NewPassword, false)
+	if err != nil {
+		fail(ctx, passwordError("new_password", err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Tokens{Session: session})
+}
+
+// deprecatedMW marks responses of v1 endpoints that have a v2 successor
+// (draft-ietf-httpapi-deprecation-header), so clients can find them in logs.
+func deprecatedMW(successor string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Deprecation", "true")
+		ctx.Header("Link", "<"+successor+">; rel=\"successor-version\"")
+		ctx.Next()
+	}
+}
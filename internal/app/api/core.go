@@ -3,53 +3,156 @@ package api
 import (
 	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
 	"task-manager-backend/docs"
 	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/health"
+	"task-manager-backend/internal/app/metrics"
+	"task-manager-backend/internal/app/queue"
 	"task-manager-backend/internal/app/service/auth"
+	"task-manager-backend/internal/app/service/mail"
+	"task-manager-backend/internal/app/service/notification"
+	"task-manager-backend/internal/app/service/profile"
+	"task-manager-backend/internal/app/service/realtime"
+	"task-manager-backend/internal/app/service/webhook"
+	"task-manager-backend/internal/app/service/workspace"
+	"time"
 )
 
-// @BasePath /api/v1/
-const BasePath = "/api/v1/"
+// @BasePath /api/
+const ApiPath = "/api/"
+
+const (
+	BasePath   = ApiPath + "v1/"
+	BasePathV2 = ApiPath + "v2/"
+)
 
 const Title = "Task manager API"
 
 type Api struct {
-	router *gin.Engine
-	auth   *auth.Service
+	server        *http.Server
+	shutdown      time.Duration
+	maxBodyBytes  int64
+	drainDelay    time.Duration
+	health        *health.Checker
+	metrics       *prometheus.Registry
+	logger        *zap.Logger
+	router        *gin.Engine
+	auth          *auth.Service
+	workspaces    *workspace.Service
+	profiles      *profile.Service
+	notifications *notification.Service
+	realtime      *realtime.Service
+	webhooks      *webhook.Service
+	queue         *queue.Queue
+	renderer      *mail.Renderer
+	transport     mail.Transport
+
+	// internal serves operator endpoints on api.internal_addr, away from
+	// the public listener.
+	internal       *http.Server
+	internalRouter *gin.Engine
+	devMailbox     bool
 }
 
-func (api *Api) Run() {
-	cfg := config.Load()
-	docs.SwaggerInfo.BasePath = BasePath
+func (api *Api) registerSwagger() {
+	docs.SwaggerInfo.BasePath = ApiPath
 	docs.SwaggerInfo.Title = Title
 
 	api.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler, ginSwagger.DefaultModelsExpandDepth(-1)))
-
-	api.router.Run(cfg.Api.GetAddr())
 }
 
 func NewApi(
+	cfg config.ServiceConfiguration,
+	store *config.Store,
 	router *gin.Engine,
 	auth *auth.Service,
-) *Api {
+	workspaces *workspace.Service,
+	profiles *profile.Service,
+	notifications *notification.Service,
+	realtime *realtime.Service,
+	webhooks *webhook.Service,
+	queue *queue.Queue,
+	renderer *mail.Renderer,
+	transport mail.Transport,
+	checker *health.Checker,
+	registry *prometheus.Registry,
+	logger *zap.Logger,
+) (*Api, error) {
+	internalRouter := NewRouter(logger)
 	svc := &Api{
-		router: router,
-		auth:   auth,
+		server: &http.Server{
+			Addr:              cfg.Api.GetAddr(),
+			Handler:           router,
+			ReadTimeout:       cfg.Api.ReadTimeout,
+			ReadHeaderTimeout: cfg.Api.ReadHeaderTimeout,
+			WriteTimeout:      cfg.Api.WriteTimeout,
+			IdleTimeout:       cfg.Api.IdleTimeout,
+		},
+		shutdown:      cfg.Api.ShutdownTimeout,
+		maxBodyBytes:  cfg.Api.MaxBodyBytes,
+		drainDelay:    cfg.Health.DrainDelay,
+		health:        checker,
+		metrics:       registry,
+		logger:        logger,
+		router:        router,
+		auth:          auth,
+		workspaces:    workspaces,
+		profiles:      profiles,
+		notifications: notifications,
+		realtime:      realtime,
+		webhooks:      webhooks,
+		queue:         queue,
+		renderer:      renderer,
+		transport:     transport,
+
+		internal: &http.Server{
+			Addr:              cfg.Api.InternalAddr,
+			Handler:           internalRouter,
+			ReadHeaderTimeout: cfg.Api.ReadHeaderTimeout,
+			IdleTimeout:       cfg.Api.IdleTimeout,
+		},
+		internalRouter: internalRouter,
+		devMailbox:     cfg.Mail.DevMailbox,
+	}
+	if err := registerValidators(); err != nil {
+		return nil, err
 	}
-	svc.router.Use(CORSMiddleware())
+	svc.router.ContextWithFallback = true
+	// MetricsMW comes before CORSMiddleware so the preflights it answers are
+	// counted too.
+	svc.router.Use(TracingMW(), LoggingMW(logger), MetricsMW(registry), CORSMiddleware(store), svc.ErrorMW())
 	svc.registerRoutes()
-	return svc
+	svc.registerSwagger()
+	return svc, nil
 }
 
-func CORSMiddleware() gin.HandlerFunc {
+// CORSMiddleware allows the configured origins, or any origin when none are
+// configured. The list is read per request, so reloading it takes effect at
+// once.
+func CORSMiddleware(store *config.Store) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		origins := store.Current().CORS.AllowedOrigins
+		if len(origins) == 0 {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Writer.Header().Add("Vary", "Origin")
+			if origin := c.GetHeader("Origin"); allowedOrigin(origins, origin) {
+				c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+		}
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Workspace-ID, X-Request-ID, Last-Event-ID, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", requestIDHeader)
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -60,27 +163,145 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
-func StartHook(lifecycle fx.Lifecycle, api *Api) {
+func allowedOrigin(origins []string, origin string) bool {
+	for _, allowed := range origins {
+		if strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// StartHook serves the API for the lifetime of the app. The addresses are
+// bound before the app counts as started, so a port in use fails the start.
+// On stop new connections are refused and requests in flight get up to
+// api.shutdown_timeout to finish; open event streams are ended right away.
+func StartHook(lifecycle fx.Lifecycle, shutdowner fx.Shutdowner, api *Api) {
+	ctx, cancel := context.WithCancel(context.Background())
+	api.server.BaseContext = func(net.Listener) context.Context {
+		return ctx
+	}
+	api.server.RegisterOnShutdown(cancel)
+
+	servers := []*http.Server{api.server}
+	if api.internal.Addr != "" {
+		servers = append(servers, api.internal)
+	}
+
+	var running sync.WaitGroup
 	lifecycle.Append(
 		fx.Hook{
-			OnStart: func(ctx context.Context) error {
-				go api.Run()
+			OnStart: func(context.Context) error {
+				listeners := make([]net.Listener, 0, len(servers))
+				for _, server := range servers {
+					listener, err := net.Listen("tcp", server.Addr)
+					if err != nil {
+						for _, listener := range listeners {
+							listener.Close()
+						}
+						return err
+					}
+					listeners = append(listeners, listener)
+				}
+
+				for i, server := range servers {
+					api.logger.Info("Api: Listening", zap.Stringer("addr", listeners[i].Addr()))
+					running.Add(1)
+					go func(server *http.Server, listener net.Listener) {
+						defer running.Done()
+						if err := server.Serve(listener); err != http.ErrServerClosed {
+							api.logger.Error("Api: Server stopped", zap.Error(err))
+							shutdowner.Shutdown()
+						}
+					}(server, listeners[i])
+				}
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				api.health.Drain()
+				select {
+				case <-time.After(api.drainDelay):
+				case <-ctx.Done():
+				}
+
+				ctx, cancel := context.WithTimeout(ctx, api.shutdown)
+				defer cancel()
+
+				for _, server := range servers {
+					if err := server.Shutdown(ctx); err != nil {
+						api.logger.Warn("Api: Requests still running after the shutdown timeout", zap.Error(err))
+						server.Close()
+					}
+				}
+				running.Wait()
 				return nil
 			},
 		})
 }
 
 func (api *Api) registerRoutes() {
+	api.router.Static(api.profiles.AvatarsURLPrefix(), api.profiles.AvatarsDir())
+	api.router.GET("/healthz", api.Healthz)
+	api.router.GET("/readyz", api.Readyz)
+	api.internalRouter.GET("/metrics", gin.WrapH(metrics.Handler(api.metrics)))
+	api.registerMailbox()
+
 	base := api.router.Group(BasePath)
+	base.GET("/stream", api.Stream)
+
 	baseWithAuth := base.Group("/")
 	baseWithAuth.Use(api.AuthMW())
+	baseWithAuth.POST("/invites", api.CreateInvite)
+	baseWithAuth.GET("/me", api.GetMe)
+	baseWithAuth.PATCH("/me", api.UpdateMe)
+	baseWithAuth.POST("/me/avatar", api.UploadAvatar)
+	baseWithAuth.GET("/notifications", api.ListNotifications)
+	baseWithAuth.GET("/notifications/unread_count", api.UnreadNotifications)
+	baseWithAuth.POST("/notifications/:notification_id/read", api.ReadNotification)
+	baseWithAuth.POST("/notifications/read_all", api.ReadAllNotifications)
+	baseWithAuth.GET("/notifications/preferences", api.GetNotificationPreferences)
+	baseWithAuth.PUT("/notifications/preferences", api.SetNotificationPreferences)
+	baseWithAuth.POST("/workspaces", api.CreateWorkspace)
+	baseWithAuth.GET("/workspaces", api.ListWorkspaces)
+
+	admin := baseWithAuth.Group("/")
+	admin.Use(api.AdminMW())
+	admin.GET("/jobs", api.ListJobs)
+	admin.POST("/jobs/:job_id/retry", api.RetryJob)
+	admin.GET("/mail/templates", api.ListMailTemplates)
+	admin.GET("/mail/preview/:template", api.PreviewMail)
+
+	inWorkspace := baseWithAuth.Group("/workspace")
+	inWorkspace.Use(api.WorkspaceMW())
+	inWorkspace.GET("/members", api.ListMembers)
+	inWorkspace.POST("/members", api.AddMember)
+	inWorkspace.POST("/webhooks", api.CreateWebhook)
+	inWorkspace.GET("/webhooks", api.ListWebhooks)
+	inWorkspace.DELETE("/webhooks/:webhook_id", api.DeleteWebhook)
+	inWorkspace.POST("/webhooks/:webhook_id/enable", api.EnableWebhook)
+	inWorkspace.GET("/webhooks/:webhook_id/deliveries", api.ListDeliveries)
+	inWorkspace.POST("/webhooks/:webhook_id/deliveries/:delivery_id/replay", api.ReplayDelivery)
 
 	auth := base.Group("/auth")
-	auth.POST("/signup", api.SignUp)
+	auth.POST("/signup", deprecatedMW(BasePathV2+"auth/signup"), api.SignUp)
+	auth.POST("/signin", deprecatedMW(BasePathV2+"auth/signin"), api.SignIn)
+	auth.POST("/new_password", deprecatedMW(BasePathV2+"auth/new_password"), api.NewPassword)
+	api.registerSessionRoutes(auth)
+
+	baseV2 := api.router.Group(BasePathV2)
+	authV2 := baseV2.Group("/auth")
+	authV2.POST("/signup", api.SignUpV2)
+	authV2.POST("/signin", api.SignInV2)
+	authV2.POST("/new_password", api.NewPasswordV2)
+	api.registerSessionRoutes(authV2)
+}
+
+// registerSessionRoutes registers the auth routes whose contract is the same
+// in every API version.
+func (api *Api) registerSessionRoutes(auth *gin.RouterGroup) {
 	auth.POST("/logout", api.Logout)
-	auth.POST("/signin", api.SignIn)
 	auth.POST("/refresh", api.Refresh)
 	auth.GET("/confirm/:confirm_token", api.Confirmation)
+	auth.POST("/resend_confirmation", api.ResendConfirmation)
 	auth.POST("/restore_password", api.RestorePassword)
-	auth.POST("/new_password", api.NewPassword)
 }
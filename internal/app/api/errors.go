@@ -0,0 +1,145 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+	"net/http"
+	"strings"
+	"task-manager-backend/internal/app/errs"
+)
+
+const problemContentType = "application/problem+json"
+
+var noRoute = errs.New(errs.NotFound, "not_found", "Not found")
+
+var errorLocales = errs.Locales()
+
+var errorLanguages = func() language.Matcher {
+	tags := make([]language.Tag, 0, len(errorLocales))
+	for _, locale := range errorLocales {
+		tags = append(tags, language.MustParse(locale))
+	}
+	return language.NewMatcher(tags)
+}()
+
+var statuses = map[errs.Kind]int{
+	errs.Internal:        http.StatusInternalServerError,
+	errs.Invalid:         http.StatusBadRequest,
+	errs.Unauthorized:    http.StatusUnauthorized,
+	errs.Forbidden:       http.StatusForbidden,
+	errs.NotFound:        http.StatusNotFound,
+	errs.Conflict:        http.StatusConflict,
+	errs.TooLarge:        http.StatusRequestEntityTooLarge,
+	errs.TooManyRequests: http.StatusTooManyRequests,
+}
+
+// legacyStatuses are the statuses v1 answered with before errors had kinds,
+// for the codes whose status has changed since.
+var legacyStatuses = map[string]int{
+	"invalid_credentials": http.StatusForbidden,
+	"email_taken":         http.StatusForbidden,
+	"already_member":      http.StatusBadRequest,
+	"body_too_large":      http.StatusBadRequest,
+}
+
+// Error is the error response of v1, which keeps its old statuses: 403 for
+// wrong credentials or a taken email, and 400 for an existing member. Send Accept: application/problem+json to get a Problem
+// instead; v2 always answers with one.
+type Error struct {
+	Err string `json:"err"`
+}
+
+// Problem is an RFC 7807 error response. Code identifies the error for
+// clients; Detail and the messages in Errors, which lists the fields that
+// failed validation, are in the language of the caller.
+type Problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail,omitempty"`
+	Code      string            `json:"code"`
+	RequestID string            `json:"request_id,omitempty"`
+	Errors    []errs.FieldError `json:"errors,omitempty"`
+}
+
+// fail stops the request with err, which ErrorMW turns into the response.
+func fail(ctx *gin.Context, err error) {
+	ctx.Error(err)
+	ctx.Abort()
+}
+
+// ErrorMW renders the error a handler failed with as application/problem+json.
+// Domain errors get the status of their kind; anything else is reported as
+// an internal error without details, which stay in the log. v1 answers with
+// an Error in English unless the caller asks for a Problem.
+func (api *Api) ErrorMW() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Written() {
+			return
+		}
+		var err error
+		switch {
+		case len(c.Errors) > 0:
+			err = c.Errors.Last().Err
+		case c.Writer.Status() == http.StatusNotFound:
+			err = noRoute
+		default:
+			return
+		}
+
+		domain, ok := errs.As(err)
+		if !ok || domain.Kind == errs.Internal {
+			domain = errs.New(errs.Internal, errs.CodeInternal, "Internal server error")
+		}
+		status := statuses[domain.Kind]
+		if strings.HasPrefix(c.Request.URL.Path, BasePath) {
+			c.Writer.Header().Add("Vary", "Accept")
+			if !strings.Contains(c.GetHeader("Accept"), problemContentType) {
+				if legacy, ok := legacyStatuses[domain.Code]; ok {
+					status = legacy
+				}
+				c.JSON(status, Error{Err: domain.Message})
+				return
+			}
+		}
+		locale := api.errorLocale(c)
+		domain = domain.Localize(locale)
+
+		c.Header("Content-Type", problemContentType)
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language, Authorization")
+		c.JSON(status, Problem{
+			Type:      "about:blank",
+			Title:     http.StatusText(status),
+			Status:    status,
+			Detail:    domain.Message,
+			Code:      domain.Code,
+			RequestID: c.Writer.Header().Get(requestIDHeader),
+			Errors:    domain.Fields,
+		})
+	}
+}
+
+// errorLocale picks the language of error messages: the locale the
+// signed-in user saved, else the best match for Accept-Language, else the
+// default.
+func (api *Api) errorLocale(c *gin.Context) string {
+	if userID, err := popUserIDfromContext(c); err == nil {
+		if saved, err := api.profiles.Locale(c, userID); err == nil {
+			base, _, _ := strings.Cut(saved, "-")
+			for _, locale := range errorLocales {
+				if locale == base {
+					return locale
+				}
+			}
+		}
+	}
+	if tags, _, err := language.ParseAcceptLanguage(c.GetHeader("Accept-Language")); err == nil && len(tags) > 0 {
+		if _, i, confidence := errorLanguages.Match(tags...); confidence > language.No {
+			return errorLocales[i]
+		}
+	}
+	return errorLocales[0]
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"task-manager-backend/internal/app/service/auth"
+	"testing"
+)
+
+func TestErrorMW(t *testing.T) {
+	api := &Api{}
+	router := gin.New()
+	router.Use(api.ErrorMW())
+	for _, path := range []string{BasePath + "auth/signin", BasePathV2 + "auth/signin"} {
+		router.POST(path, func(ctx *gin.Context) {
+			fail(ctx, auth.IncorrectCreds)
+		})
+	}
+
+	for _, tt := range []struct {
+		name   string
+		path   string
+		accept string
+		status int
+		body   string
+	}{
+		{"v1", BasePath + "auth/signin", "", http.StatusForbidden, `{"err":"Incorrect login or password"}`},
+		{"v1 asking for a problem", BasePath + "auth/signin", problemContentType, http.StatusUnauthorized, ""},
+		{"v2", BasePathV2 + "auth/signin", "", http.StatusUnauthorized, ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d", rec.Code, tt.status)
+			}
+			if tt.body != "" {
+				if rec.Body.String() != tt.body {
+					t.Fatalf("body %s, want %s", rec.Body, tt.body)
+				}
+				return
+			}
+			var problem Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if got := rec.Header().Get("Content-Type"); got != problemContentType {
+				t.Fatalf("content type %q, want %q", got, problemContentType)
+			}
+			if problem.Status != tt.status || problem.Code != "invalid_credentials" {
+				t.Fatalf("problem %+v, want invalid_credentials with status %d", problem, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"task-manager-backend/internal/app/health"
+)
+
+// Healthz reports that the process is alive. It checks no dependencies, so
+// an outage of one does not get the service restarted.
+func (api *Api) Healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": health.StatusUp})
+}
+
+// Readyz reports whether the service can take traffic, with the status of
+// every dependency.
+func (api *Api) Readyz(ctx *gin.Context) {
+	report := api.health.Check(ctx.Request.Context())
+	if !report.Ready {
+		ctx.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
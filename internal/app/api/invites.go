@@ -0,0 +1,52 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/models/workspaces"
+)
+
+type Invite struct {
+	Email       users.Email     `json:"email" binding:"required,email"`
+	WorkspaceID workspaces.ID   `json:"workspace_id,omitempty" binding:"omitempty,id"`
+	Role        workspaces.Role `json:"role,omitempty" binding:"omitempty,enum"`
+}
+
+// CreateInvite godoc
+// @Summary Приглашение пользователя
+// @Schemes
+// @Description Отправляет приглашение на регистрацию
+// @Description Без рабочего пространства доступно администраторам, с ним - его владельцу
+// @Description и администраторам. Приглашённый сразу становится участником с указанной ролью
+// @Tags auth
+// @Accept json
+// @Security ApiKeyAuth
+// @Param data body Invite true "Входные параметры"
+// @Success 200
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 403 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/invites [post]
+func (api *Api) CreateInvite(ctx *gin.Context) {
+	userID, err := popUserIDfromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var req Invite
+	if err := api.bindJSON(ctx, &req); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	err = api.auth.CreateInvite(ctx, userID, req.Email, req.WorkspaceID, req.Role)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.AbortWithStatus(http.StatusOK)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"task-manager-backend/internal/app/models/jobs"
+)
+
+type JobsQuery struct {
+	Status jobs.Status `form:"status" binding:"omitempty,enum"`
+	Limit  uint64      `form:"limit" binding:"max=100"`
+	Offset uint64      `form:"offset"`
+}
+
+type Jobs struct {
+	Jobs []jobs.Job `json:"jobs"`
+}
+
+type JobURI struct {
+	ID jobs.ID `uri:"job_id" binding:"id"`
+}
+
+// ListJobs godoc
+// @Summary Список фоновых задач
+// @Schemes
+// @Description Возвращает фоновые задачи с указанным статусом, по умолчанию — упавшие (dead) после всех попыток
+// @Description Полезная нагрузка задач не возвращается: в ней бывают токены из писем
+// @Description Доступно только администраторам
+// @Tags jobs
+// @Produce json
+// @Security ApiKeyAuth
+// @Param status query string false "pending, running или dead"
+// @Param limit query int false "Количество, по умолчанию 20, не больше 100"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} Jobs
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 403 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/jobs [get]
+func (api *Api) ListJobs(ctx *gin.Context) {
+	var query JobsQuery
+	if err := bindQuery(ctx, &query); err != nil {
+		fail(ctx, err)
+		return
+	}
+	if query.Status == "" {
+		query.Status = jobs.Dead
+	}
+
+	list, err := api.queue.List(ctx, query.Status, query.Limit, query.Offset)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Jobs{Jobs: list})
+}
+
+// RetryJob godoc
+// @Summary Повтор упавшей задачи
+// @Schemes
+// @Description Возвращает упавшую задачу в очередь с новым набором попыток
+// @Description Задачу с ключом нельзя повторить, пока в очереди ждёт другая с тем же ключом (job_key_pending)
+// @Description Доступно только администраторам
+// @Tags jobs
+// @Produce json
+// @Security ApiKeyAuth
+// @Param job_id path int true "Идентификатор задачи"
+// @Success 200 {object} jobs.Job
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 403 {object} Error
+// @Failure 404 {object} Error
+// @Failure 409 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/jobs/{job_id}/retry [post]
+func (api *Api) RetryJob(ctx *gin.Context) {
+	var uri JobURI
+	if err := bindURI(ctx, &uri); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	job, err := api.queue.Retry(ctx, uri.ID)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
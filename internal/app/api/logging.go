@@ -0,0 +1,89 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"regexp"
+	"task-manager-backend/internal/app/logging"
+	"time"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// validRequestID limits the request IDs taken from callers, so that they
+// cant inject anything into the logs.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// NewRouter returns the engine without gin's own request logger, which
+// prints raw paths and queries; requests are logged by LoggingMW instead.
+func NewRouter(logger *zap.Logger) *gin.Engine {
+	// Request bodies with fields the API does not know are rejected rather
+	// than half applied. The switch is global to gin's binding package, so
+	// it is set here, before any router can bind a request.
+	binding.EnableDecoderDisallowUnknownFields = true
+
+	router := gin.New()
+	router.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered interface{}) {
+		logging.FromContext(c, logger).Error("Api: Panic while serving request",
+			zap.Any("panic", recovered), zap.Stack("stack"))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	return router
+}
+
+// LoggingMW gives every request an ID, taken from the X-Request-ID header
+// when the caller sent a usable one, and puts a logger tagged with it, the
+// route and the trace into the request context. Once the request is served
+// it logs the outcome. Only the route template is logged: paths, queries
+// and headers may carry tokens.
+func LoggingMW(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+
+		requestID := c.GetHeader(requestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(requestIDHeader, requestID)
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		fields := []zap.Field{
+			zap.String("request_id", requestID),
+			zap.String("method", c.Request.Method),
+			zap.String("route", route),
+		}
+		if span := trace.SpanContextFromContext(c.Request.Context()); span.HasTraceID() {
+			fields = append(fields, zap.Stringer("trace_id", span.TraceID()))
+		}
+		c.Request = c.Request.WithContext(logging.WithContext(c.Request.Context(), logger.With(fields...)))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		entry := logging.FromContext(c, logger)
+		fields = []zap.Field{
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(started)),
+			zap.Int("size", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+		switch {
+		case status >= 500:
+			entry.Error("Api: Request failed", fields...)
+		case status >= 400:
+			entry.Info("Api: Request rejected", fields...)
+		default:
+			entry.Info("Api: Request served", fields...)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/service/mail"
+)
+
+const (
+	previewHTML = "html"
+	previewText = "text"
+)
+
+type MailTemplates struct {
+	Templates []string `json:"templates"`
+}
+
+type MailPreviewURI struct {
+	Template string `uri:"template" binding:"required"`
+}
+
+type MailPreviewQuery struct {
+	Locale string `form:"locale" binding:"omitempty,locale"`
+	Format string `form:"format" binding:"omitempty,oneof=html text"`
+}
+
+// ListMailTemplates godoc
+// @Summary Список шаблонов писем
+// @Schemes
+// @Description Доступно только администраторам
+// @Tags mail
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} MailTemplates
+// @Failure 401 {object} Error
+// @Failure 403 {object} Error
+// @Router /v1/mail/templates [get]
+func (api *Api) ListMailTemplates(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, MailTemplates{Templates: api.renderer.Templates()})
+}
+
+// PreviewMail godoc
+// @Summary Предпросмотр письма
+// @Schemes
+// @Description Отрисовывает шаблон письма на тестовых данных
+// @Description Без format возвращает тему и обе версии письма, с format=html или format=text — только одну версию как есть
+// @Description Доступно только администраторам
+// @Tags mail
+// @Produce json,html,plain
+// @Security ApiKeyAuth
+// @Param template path string true "Имя шаблона"
+// @Param locale query string false "Язык, по умолчанию ru"
+// @Param format query string false "html или text"
+// @Success 200 {object} mail.Message
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 403 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/mail/preview/{template} [get]
+func (api *Api) PreviewMail(ctx *gin.Context) {
+	var uri MailPreviewURI
+	if err := bindURI(ctx, &uri); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var query MailPreviewQuery
+	if err := bindQuery(ctx, &query); err != nil {
+		fail(ctx, err)
+		return
+	}
+	if query.Locale == "" {
+		query.Locale = users.DefaultLocale
+	}
+
+	msg, err := api.renderer.Render(uri.Template, query.Locale, "user@example.com", mail.Samples[uri.Template])
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	switch query.Format {
+	case previewHTML:
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(msg.HTML))
+	case previewText:
+		ctx.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(msg.Text))
+	default:
+		ctx.JSON(http.StatusOK, msg)
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"html/template"
+	"net/http"
+	"strconv"
+	"task-manager-backend/internal/app/service/mail"
+)
+
+const mailboxPath = "/dev/mailbox"
+
+var mailboxPage = template.Must(template.New("mailbox").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Mailbox</title>
+<style>
+body { font-family: Arial, sans-serif; margin: 0; display: flex; height: 100vh; }
+nav { width: 360px; overflow-y: auto; border-right: 1px solid #ddd; }
+nav a { display: block; padding: 10px 14px; color: #222; text-decoration: none; border-bottom: 1px solid #eee; }
+nav a.active { background: #eef4ff; }
+nav small { color: #888; display: block; }
+main { flex: 1; display: flex; flex-direction: column; }
+header { padding: 10px 14px; border-bottom: 1px solid #ddd; }
+iframe { flex: 1; border: 0; }
+pre { padding: 14px; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<nav>
+<form method="post" action="{{.Path}}/clear"><button type="submit">Clear</button></form>
+{{range .Messages}}<a href="{{$.Path}}?id={{.ID}}"{{if and $.Current (eq .ID $.Current.ID)}} class="active"{{end}}>{{.Subject}}<small>{{range .To}}{{.}} {{end}}· {{.SentAt.Format "15:04:05"}}</small></a>
+{{else}}<p style="padding: 14px">No mail yet</p>{{end}}
+</nav>
+<main>
+{{with .Current}}<header><b>{{.Subject}}</b><br>From: {{.From}} · To: {{range .To}}{{.}} {{end}}· <a href="{{$.Path}}/{{.ID}}/raw">raw</a></header>
+{{if .HTML}}<iframe sandbox src="{{$.Path}}/{{.ID}}/html"></iframe>{{end}}
+<pre>{{.Text}}</pre>{{end}}
+</main>
+</body>
+</html>
+`))
+
+type mailboxView struct {
+	Path     string
+	Messages []mail.Stored
+	Current  *mail.Stored
+}
+
+// registerMailbox serves the messages kept by the in-memory mail transport
+// on the internal listener. It exists only when mail.dev_mailbox is on,
+// which configuration validation allows with that transport alone.
+func (api *Api) registerMailbox() {
+	mailbox, ok := api.transport.(*mail.MemoryTransport)
+	if !api.devMailbox || !ok {
+		return
+	}
+
+	api.internalRouter.GET(mailboxPath, func(ctx *gin.Context) {
+		view := mailboxView{Path: mailboxPath, Messages: mailbox.Messages()}
+		if id, err := strconv.Atoi(ctx.Query("id")); err == nil {
+			if stored, ok := mailbox.Message(id); ok {
+				view.Current = &stored
+			}
+		} else if len(view.Messages) > 0 {
+			view.Current = &view.Messages[0]
+		}
+
+		ctx.Status(http.StatusOK)
+		ctx.Header("Content-Type", "text/html; charset=utf-8")
+		if err := mailboxPage.Execute(ctx.Writer, view); err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+		}
+	})
+
+	api.internalRouter.GET(mailboxPath+"/:id/html", func(ctx *gin.Context) {
+		id, _ := strconv.Atoi(ctx.Param("id"))
+		stored, ok := mailbox.Message(id)
+		if !ok {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(stored.HTML))
+	})
+
+	api.internalRouter.GET(mailboxPath+"/:id/raw", func(ctx *gin.Context) {
+		id, _ := strconv.Atoi(ctx.Param("id"))
+		stored, ok := mailbox.Message(id)
+		if !ok {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		ctx.Data(http.StatusOK, "message/rfc822", []byte(stored.Raw))
+	})
+
+	api.internalRouter.POST(mailboxPath+"/clear", func(ctx *gin.Context) {
+		mailbox.Clear()
+		ctx.Redirect(http.StatusSeeOther, mailboxPath)
+	})
+}
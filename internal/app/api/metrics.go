@@ -0,0 +1,45 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"strconv"
+	"task-manager-backend/internal/app/metrics"
+	"time"
+)
+
+// unmatchedRoute labels requests no route matched, so that scanners cant
+// create a series per path they try.
+const unmatchedRoute = "unmatched"
+
+// MetricsMW counts requests and measures their latency by route template
+// and status.
+func MetricsMW(registerer prometheus.Registerer) gin.HandlerFunc {
+	factory := promauto.With(registerer)
+	requests := factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route template and status.",
+	}, []string{"method", "route", "status"})
+	duration := factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status.",
+	}, []string{"method", "route", "status"})
+
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		requests.WithLabelValues(c.Request.Method, route, status).Inc()
+		duration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(started).Seconds())
+	}
+}
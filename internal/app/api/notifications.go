@@ -0,0 +1,214 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"task-manager-backend/internal/app/models/notifications"
+)
+
+type NotificationsQuery struct {
+	UnreadOnly bool   `form:"unread_only"`
+	Limit      uint64 `form:"limit" binding:"max=100"`
+	Offset     uint64 `form:"offset"`
+}
+
+type Notifications struct {
+	Notifications []notifications.Notification `json:"notifications"`
+	Unread        int64                        `json:"unread"`
+}
+
+type UnreadCount struct {
+	Unread int64 `json:"unread"`
+}
+
+type NotificationURI struct {
+	ID notifications.ID `uri:"notification_id" binding:"id"`
+}
+
+type NotificationPreferences struct {
+	Preferences []notifications.Preference `json:"preferences" binding:"required,dive"`
+}
+
+// ListNotifications godoc
+// @Summary Список уведомлений
+// @Schemes
+// @Description Возвращает уведомления пользователя, начиная с новых, и число непрочитанных
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Param unread_only query bool false "Только непрочитанные"
+// @Param limit query int false "Количество, по умолчанию 20, не больше 100"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} Notifications
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/notifications [get]
+func (api *Api) ListNotifications(ctx *gin.Context) {
+	userID, err := popUserIDfromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var query NotificationsQuery
+	if err := bindQuery(ctx, &query); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	list, unread, err := api.notifications.List(ctx, userID, query.UnreadOnly, query.Limit, query.Offset)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Notifications{Notifications: list, Unread: unread})
+}
+
+// UnreadNotifications godoc
+// @Summary Число непрочитанных уведомлений
+// @Schemes
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} UnreadCount
+// @Failure 401 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/notifications/unread_count [get]
+func (api *Api) UnreadNotifications(ctx *gin.Context) {
+	userID, err := popUserIDfromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	unread, err := api.notifications.UnreadCount(ctx, userID)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, UnreadCount{Unread: unread})
+}
+
+// ReadNotification godoc
+// @Summary Отметить уведомление прочитанным
+// @Schemes
+// @Tags notifications
+// @Security ApiKeyAuth
+// @Param notification_id path int true "ID уведомления"
+// @Success 200
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/notifications/{notification_id}/read [post]
+func (api *Api) ReadNotification(ctx *gin.Context) {
+	userID, err := popUserIDfromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var uri NotificationURI
+	if err := bindURI(ctx, &uri); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	err = api.notifications.MarkRead(ctx, userID, uri.ID)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.AbortWithStatus(http.StatusOK)
+}
+
+// ReadAllNotifications godoc
+// @Summary Отметить все уведомления прочитанными
+// @Schemes
+// @Tags notifications
+// @Security ApiKeyAuth
+// @Success 200
+// @Failure 401 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/notifications/read_all [post]
+func (api *Api) ReadAllNotifications(ctx *gin.Context) {
+	userID, err := popUserIDfromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	if err = api.notifications.MarkAllRead(ctx, userID); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.AbortWithStatus(http.StatusOK)
+}
+
+// GetNotificationPreferences godoc
+// @Summary Настройки уведомлений
+// @Schemes
+// @Description Возвращает канал доставки (in_app, email, none) для каждого типа событий
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} NotificationPreferences
+// @Failure 401 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/notifications/preferences [get]
+func (api *Api) GetNotificationPreferences(ctx *gin.Context) {
+	userID, err := popUserIDfromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	preferences, err := api.notifications.Preferences(ctx, userID)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NotificationPreferences{Preferences: preferences})
+}
+
+// SetNotificationPreferences godoc
+// @Summary Изменение настроек уведомлений
+// @Schemes
+// @Description Задаёт канал доставки для переданных типов событий
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body NotificationPreferences true "Входные параметры"
+// @Success 200 {object} NotificationPreferences
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/notifications/preferences [put]
+func (api *Api) SetNotificationPreferences(ctx *gin.Context) {
+	userID, err := popUserIDfromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var req NotificationPreferences
+	if err := api.bindJSON(ctx, &req); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	preferences, err := api.notifications.SetPreferences(ctx, userID, req.Preferences)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, NotificationPreferences{Preferences: preferences})
+}
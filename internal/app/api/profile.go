@@ -0,0 +1,136 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/service/profile"
+)
+
+const (
+	avatarFormField = "avatar"
+	// multipartOverhead allows for the multipart headers around the avatar.
+	multipartOverhead = 64 << 10
+)
+
+// GetMe godoc
+// @Summary Профиль текущего пользователя
+// @Schemes
+// @Description Возвращает профиль пользователя, выполнившего запрос
+// @Tags profile
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} users.Profile
+// @Failure 401 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/me [get]
+func (api *Api) GetMe(ctx *gin.Context) {
+	userID, err := popUserIDfromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	me, err := api.profiles.Get(ctx, userID)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, me)
+}
+
+// UpdateMe godoc
+// @Summary Изменение профиля
+// @Schemes
+// @Description Изменяет переданные поля профиля текущего пользователя
+// @Description Часовой пояс задаётся именем из базы IANA, язык - тегом BCP 47
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body users.ProfileUpdate true "Входные параметры"
+// @Success 200 {object} users.Profile
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/me [patch]
+func (api *Api) UpdateMe(ctx *gin.Context) {
+	userID, err := popUserIDfromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var req users.ProfileUpdate
+	if err := api.bindJSON(ctx, &req); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	me, err := api.profiles.Update(ctx, userID, req)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, me)
+}
+
+// UploadAvatar godoc
+// @Summary Загрузка аватара
+// @Schemes
+// @Description Принимает изображение JPEG, PNG или GIF размером до 5 МБ
+// @Description Изображение обрезается до квадрата и сохраняется в нескольких размерах
+// @Tags profile
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param avatar formData file true "Изображение"
+// @Success 200 {object} users.Profile
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 404 {object} Error
+// @Failure 413 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/me/avatar [post]
+func (api *Api) UploadAvatar(ctx *gin.Context) {
+	userID, err := popUserIDfromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, profile.MaxAvatarBytes+multipartOverhead)
+	header, err := ctx.FormFile(avatarFormField)
+	var sizeErr *http.MaxBytesError
+	if errors.As(err, &sizeErr) {
+		fail(ctx, profile.AvatarTooLarge)
+		return
+	} else if err != nil {
+		fail(ctx, profile.InvalidImage)
+		return
+	}
+	if header.Size > profile.MaxAvatarBytes {
+		fail(ctx, profile.AvatarTooLarge)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+	defer file.Close()
+
+	me, err := api.profiles.UploadAvatar(ctx, userID, file)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, me)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"io"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/service/auth"
+	"task-manager-backend/internal/app/service/realtime"
+	"time"
+)
+
+const (
+	lastEventIDHeader = "Last-Event-ID"
+	lastEventIDQuery  = "last_event_id"
+	accessTokenQuery  = "access_token"
+	keepAliveInterval = 25 * time.Second
+)
+
+// Stream godoc
+// @Summary Поток событий
+// @Schemes
+// @Description Server-Sent Events с событиями задач, комментариев и досок
+// @Description во всех рабочих пространствах пользователя.
+// @Description Браузерный EventSource не умеет передавать заголовки, поэтому токен
+// @Description можно передать параметром access_token. После переподключения
+// @Description пропущенные события досылаются начиная с Last-Event-ID.
+// @Tags realtime
+// @Produce text/event-stream
+// @Security ApiKeyAuth
+// @Param access_token query string false "JWT, если нельзя передать заголовок Authorization"
+// @Param Last-Event-ID header string false "ID последнего полученного события"
+// @Param last_event_id query string false "То же, что заголовок Last-Event-ID"
+// @Success 200
+// @Failure 401 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/stream [get]
+func (api *Api) Stream(ctx *gin.Context) {
+	token, err := extractAuthToken(ctx)
+	if err != nil {
+		token = ctx.Query(accessTokenQuery)
+	}
+
+	userID, err := api.auth.UnmarshalToken(token)
+	if err != nil {
+		fail(ctx, auth.Unauthenticated)
+		return
+	}
+
+	lastEventID := ctx.GetHeader(lastEventIDHeader)
+	if lastEventID == "" {
+		lastEventID = ctx.Query(lastEventIDQuery)
+	}
+
+	sub, replay, err := api.realtime.Subscribe(ctx, userID, lastEventID)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+	defer sub.Close()
+
+	// Set before anything is written: the flush below sends the headers even
+	// when there is nothing to replay.
+	ctx.Header("Content-Type", sse.ContentType)
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	for _, record := range replay {
+		renderEvent(ctx, record)
+		lastEventID = record.ID
+	}
+	ctx.Writer.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case record, ok := <-sub.C:
+			if !ok {
+				return false
+			}
+			// Events replayed above may also arrive live.
+			if !realtime.IsAfter(record.ID, lastEventID) {
+				return true
+			}
+			renderEvent(ctx, record)
+			lastEventID = record.ID
+			return true
+		}
+	})
+}
+
+func renderEvent(ctx *gin.Context, record events.Record) {
+	ctx.Render(-1, sse.Event{
+		Id:    record.ID,
+		Event: string(record.Event.Type),
+		Data:  record.Event,
+	})
+}
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/language"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"task-manager-backend/internal/app/errs"
+	"task-manager-backend/internal/app/models/jobs"
+	"task-manager-backend/internal/app/models/notifications"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/models/workspaces"
+	"time"
+)
+
+const unknownFieldPrefix = "json: unknown field "
+
+var (
+	malformedBody  = errs.New(errs.Invalid, "malformed_body", "Request body is not valid JSON")
+	bodyTooLarge   = errs.New(errs.TooLarge, "body_too_large", "Request body is too large")
+	invalidRequest = errs.New(errs.Invalid, errs.CodeInvalid, "Invalid data")
+)
+
+// enums validates the enumerated types of the models for the enum tag.
+var enums = map[reflect.Type]func(string) bool{
+	reflect.TypeOf(workspaces.Role("")): func(v string) bool {
+		return workspaces.ValidateRole(workspaces.Role(v))
+	},
+	reflect.TypeOf(jobs.Status("")): func(v string) bool {
+		return jobs.ValidateStatus(jobs.Status(v))
+	},
+	reflect.TypeOf(notifications.Channel("")): func(v string) bool {
+		return notifications.ValidateChannel(notifications.Channel(v))
+	},
+}
+
+// validationCodes are the error codes of the failed validation tags. Tags
+// not listed report invalid_value.
+var validationCodes = map[string]string{
+	"required": "required",
+	"email":    "invalid_email",
+	"id":       "invalid_id",
+	"enum":     "invalid_value",
+	"oneof":    "not_one_of",
+	"url":      "invalid_url",
+	"timezone": "invalid_timezone",
+	"locale":   "invalid_locale",
+}
+
+// limits describe failed min and max tags on strings, on lists, marked
+// with [], and on numbers, marked with #.
+var limits = map[string]struct {
+	code    string
+	message string
+}{
+	"min":   {"too_short", "Must be at least %s characters long"},
+	"max":   {"too_long", "Must be at most %s characters long"},
+	"min[]": {"too_few", "Must have at least %s items"},
+	"max[]": {"too_many", "Must have at most %s items"},
+	"min#":  {"too_small", "Must be at least %s"},
+	"max#":  {"too_large", "Must be at most %s"},
+}
+
+// registerValidators adds the rules of the API to gin's validator, which
+// checks the binding tags of the request types, and has it name fields as
+// they appear in requests.
+func registerValidators() error {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("api: gin validates with %T, not validator/v10", binding.Validator.Engine())
+	}
+	validate.RegisterTagNameFunc(requestName)
+	for tag, fn := range map[string]validator.Func{
+		"email": func(fl validator.FieldLevel) bool {
+			return users.ValidateEmail(users.Email(fl.Field().String()))
+		},
+		"id": func(fl validator.FieldLevel) bool {
+			switch fl.Field().Kind() {
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				return fl.Field().Uint() > 0
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return fl.Field().Int() > 0
+			}
+			return false
+		},
+		"enum": func(fl validator.FieldLevel) bool {
+			valid, ok := enums[fl.Field().Type()]
+			return ok && valid(fl.Field().String())
+		},
+		"timezone": func(fl validator.FieldLevel) bool {
+			name := fl.Field().String()
+			_, err := time.LoadLocation(name)
+			return err == nil && name != "" && name != "Local"
+		},
+		"locale": func(fl validator.FieldLevel) bool {
+			_, err := language.Parse(fl.Field().String())
+			return err == nil
+		},
+	} {
+		if err := validate.RegisterValidation(tag, fn); err != nil {
+			return fmt.Errorf("api: cant register the %s validation: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// requestName is the name of the field in the JSON body, URI or query.
+func requestName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "uri", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// bindJSON decodes the JSON body into obj and validates it. Bodies over
+// api.max_body_bytes are rejected.
+func (api *Api) bindJSON(ctx *gin.Context, obj interface{}) error {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, api.maxBodyBytes)
+	if err := ctx.ShouldBindJSON(obj); err != nil {
+		return bindError(err)
+	}
+	return nil
+}
+
+// bindURI binds the path params into obj and validates them.
+func bindURI(ctx *gin.Context, obj interface{}) error {
+	if err := ctx.ShouldBindUri(obj); err != nil {
+		return bindError(paramError(err, obj, "uri", ctx.Param))
+	}
+	return nil
+}
+
+// bindQuery binds the query into obj and validates it.
+func bindQuery(ctx *gin.Context, obj interface{}) error {
+	if err := ctx.ShouldBindQuery(obj); err != nil {
+		return bindError(paramError(err, obj, "form", ctx.Query))
+	}
+	return nil
+}
+
+// paramTypeError is a path or query param that does not parse as the type
+// of its field.
+type paramTypeError struct {
+	field string
+	kind  reflect.Kind
+}
+
+func (e *paramTypeError) Error() string {
+	return fmt.Sprintf("%s is not %s", e.field, e.kind)
+}
+
+// paramError names the field of a param that failed to parse. gin reports
+// only the parse error, so the field is the one whose raw value, read with
+// value by the name in tag, is the one that was rejected.
+func paramError(err error, obj interface{}, tag string, value func(string) string) error {
+	var numErr *strconv.NumError
+	var timeErr *time.ParseError
+	var raw string
+	switch {
+	case errors.As(err, &numErr):
+		raw = numErr.Num
+	case errors.As(err, &timeErr):
+		raw = timeErr.Value
+	default:
+		return err
+	}
+
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return err
+	}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get(tag), ",")
+		if name != "" && name != "-" && value(name) == raw {
+			return &paramTypeError{field: name, kind: t.Field(i).Type.Kind()}
+		}
+	}
+	return err
+}
+
+// bindError describes why the request could not be bound, listing every
+// failing field where possible.
+func bindError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var sizeErr *http.MaxBytesError
+	var paramErr *paramTypeError
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &sizeErr):
+		return bodyTooLarge
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &syntaxErr):
+		return malformedBody
+	case errors.As(err, &typeErr):
+		return errs.Validation(errs.FieldError{
+			Field:   typeErr.Field,
+			Code:    "invalid_type",
+			Message: "Must be " + typeErr.Type.String(),
+			Params:  map[string]string{"type": typeErr.Type.String()},
+		})
+	case errors.As(err, &paramErr):
+		return errs.Validation(errs.FieldError{
+			Field:   paramErr.field,
+			Code:    "invalid_type",
+			Message: "Must be " + paramErr.kind.String(),
+			Params:  map[string]string{"type": paramErr.kind.String()},
+		})
+	case strings.HasPrefix(err.Error(), unknownFieldPrefix):
+		field, unquoteErr := strconv.Unquote(strings.TrimPrefix(err.Error(), unknownFieldPrefix))
+		if unquoteErr != nil {
+			return invalidRequest
+		}
+		return errs.Validation(errs.FieldError{Field: field, Code: "unknown_field", Message: "Unknown field"})
+	case errors.As(err, &validationErrs):
+		fields := make([]errs.FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fields = append(fields, fieldError(fieldErr))
+		}
+		return errs.Validation(fields...)
+	default:
+		return invalidRequest
+	}
+}
+
+func fieldError(fieldErr validator.FieldError) errs.FieldError {
+	// The namespace starts with the name of the request type.
+	_, field, _ := strings.Cut(fieldErr.Namespace(), ".")
+
+	switch fieldErr.Tag() {
+	case "min", "max":
+		key := fieldErr.Tag()
+		switch fieldErr.Kind() {
+		case reflect.String:
+		case reflect.Slice, reflect.Array, reflect.Map:
+			key += "[]"
+		default:
+			key += "#"
+		}
+		limit := limits[key]
+		return errs.FieldError{
+			Field:   field,
+			Code:    limit.code,
+			Message: fmt.Sprintf(limit.message, fieldErr.Param()),
+			Params:  map[string]string{fieldErr.Tag(): fieldErr.Param()},
+		}
+	case "oneof":
+		values := strings.Join(strings.Fields(fieldErr.Param()), ", ")
+		return errs.FieldError{
+			Field:   field,
+			Code:    validationCodes["oneof"],
+			Message: "Must be one of " + values,
+			Params:  map[string]string{"values": values},
+		}
+	}
+
+	code, ok := validationCodes[fieldErr.Tag()]
+	if !ok {
+		code = "invalid_value"
+	}
+	return errs.FieldError{Field: field, Code: code, Message: "Invalid value"}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"task-manager-backend/internal/app/errs"
+	"task-manager-backend/internal/app/models/users"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	if err := registerValidators(); err != nil {
+		panic(err)
+	}
+	m.Run()
+}
+
+// fields returns the field errors of a validation error.
+func fields(t *testing.T, err error) []errs.FieldError {
+	t.Helper()
+	domain, ok := errs.As(err)
+	if !ok || domain.Code != errs.CodeValidation {
+		t.Fatalf("got %v, want a validation error", err)
+	}
+	return domain.Fields
+}
+
+func TestBindError(t *testing.T) {
+	uint64Type := reflect.TypeOf(uint64(0))
+	for _, tt := range []struct {
+		name   string
+		err    error
+		want   error
+		fields []errs.FieldError
+	}{
+		{"empty body", io.EOF, malformedBody, nil},
+		{"truncated body", io.ErrUnexpectedEOF, malformedBody, nil},
+		{"syntax", &json.SyntaxError{}, malformedBody, nil},
+		{"too large", &http.MaxBytesError{Limit: 1}, bodyTooLarge, nil},
+		{"json type", &json.UnmarshalTypeError{Field: "limit", Type: uint64Type}, nil, []errs.FieldError{
+			{Field: "limit", Code: "invalid_type", Message: "Must be uint64", Params: map[string]string{"type": "uint64"}},
+		}},
+		{"param type", &paramTypeError{field: "webhook_id", kind: reflect.Uint64}, nil, []errs.FieldError{
+			{Field: "webhook_id", Code: "invalid_type", Message: "Must be uint64", Params: map[string]string{"type": "uint64"}},
+		}},
+		{"unknown field", errors.New(`json: unknown field "admin"`), nil, []errs.FieldError{
+			{Field: "admin", Code: "unknown_field", Message: "Unknown field"},
+		}},
+		{"other", errors.New("failing"), invalidRequest, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bindError(tt.err)
+			if tt.want != nil {
+				if got != tt.want {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+				return
+			}
+			if fields := fields(t, got); !reflect.DeepEqual(fields, tt.fields) {
+				t.Fatalf("got %+v, want %+v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+type fieldErrorRequest struct {
+	Email users.Email `json:"email" binding:"omitempty,email"`
+	Name  string      `json:"name" binding:"omitempty,min=2,max=3"`
+	Tags  []string    `json:"tags" binding:"omitempty,max=1"`
+	Limit uint64      `form:"limit" binding:"max=100"`
+	Role  string      `json:"role" binding:"omitempty,oneof=admin member"`
+	ID    uint64      `uri:"id" binding:"required"`
+}
+
+func TestFieldError(t *testing.T) {
+	valid := fieldErrorRequest{ID: 1}
+	for _, tt := range []struct {
+		name   string
+		modify func(*fieldErrorRequest)
+		want   errs.FieldError
+	}{
+		{"required", func(r *fieldErrorRequest) { r.ID = 0 },
+			errs.FieldError{Field: "id", Code: "required", Message: "Invalid value"}},
+		{"email", func(r *fieldErrorRequest) { r.Email = "mail" },
+			errs.FieldError{Field: "email", Code: "invalid_email", Message: "Invalid value"}},
+		{"short string", func(r *fieldErrorRequest) { r.Name = "a" },
+			errs.FieldError{Field: "name", Code: "too_short", Message: "Must be at least 2 characters long", Params: map[string]string{"min": "2"}}},
+		{"long string", func(r *fieldErrorRequest) { r.Name = "abcd" },
+			errs.FieldError{Field: "name", Code: "too_long", Message: "Must be at most 3 characters long", Params: map[string]string{"max": "3"}}},
+		{"long list", func(r *fieldErrorRequest) { r.Tags = []string{"a", "b"} },
+			errs.FieldError{Field: "tags", Code: "too_many", Message: "Must have at most 1 items", Params: map[string]string{"max": "1"}}},
+		{"large number", func(r *fieldErrorRequest) { r.Limit = 101 },
+			errs.FieldError{Field: "limit", Code: "too_large", Message: "Must be at most 100", Params: map[string]string{"max": "100"}}},
+		{"oneof", func(r *fieldErrorRequest) { r.Role = "owner" },
+			errs.FieldError{Field: "role", Code: "not_one_of", Message: "Must be one of admin, member", Params: map[string]string{"values": "admin, member"}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.modify(&req)
+			err := binding.Validator.ValidateStruct(req)
+			if err == nil {
+				t.Fatal("request passed validation")
+			}
+			if got := fields(t, bindError(err)); !reflect.DeepEqual(got, []errs.FieldError{tt.want}) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBindParams(t *testing.T) {
+	router := gin.New()
+	var bound error
+	router.GET("/webhooks/:webhook_id", func(ctx *gin.Context) {
+		var uri WebhookURI
+		bound = bindURI(ctx, &uri)
+	})
+	router.GET("/notifications", func(ctx *gin.Context) {
+		var query NotificationsQuery
+		bound = bindQuery(ctx, &query)
+	})
+
+	for _, tt := range []struct {
+		path string
+		want errs.FieldError
+	}{
+		{"/webhooks/abc", errs.FieldError{Field: "webhook_id", Code: "invalid_type", Message: "Must be uint64", Params: map[string]string{"type": "uint64"}}},
+		{"/webhooks/0", errs.FieldError{Field: "webhook_id", Code: "invalid_id", Message: "Invalid value"}},
+		{"/notifications?limit=x", errs.FieldError{Field: "limit", Code: "invalid_type", Message: "Must be uint64", Params: map[string]string{"type": "uint64"}}},
+		{"/notifications?unread_only=maybe", errs.FieldError{Field: "unread_only", Code: "invalid_type", Message: "Must be bool", Params: map[string]string{"type": "bool"}}},
+		{"/notifications?limit=101", errs.FieldError{Field: "limit", Code: "too_large", Message: "Must be at most 100", Params: map[string]string{"max": "100"}}},
+	} {
+		t.Run(tt.path, func(t *testing.T) {
+			bound = nil
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if got := fields(t, bound); !reflect.DeepEqual(got, []errs.FieldError{tt.want}) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	bound = errors.New("not bound")
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/notifications?limit=100&unread_only=true", nil))
+	if bound != nil {
+		t.Fatalf("valid query: %v", bound)
+	}
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"task-manager-backend/internal/app/models/webhooks"
+)
+
+type CreateWebhook struct {
+	URL        string   `json:"url" binding:"required,url"`
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+}
+
+type Webhooks struct {
+	Webhooks []webhooks.Webhook `json:"webhooks"`
+}
+
+type WebhookURI struct {
+	ID webhooks.ID `uri:"webhook_id" binding:"id"`
+}
+
+type DeliveryURI struct {
+	WebhookID webhooks.ID         `uri:"webhook_id" binding:"id"`
+	ID        webhooks.DeliveryID `uri:"delivery_id" binding:"id"`
+}
+
+type DeliveriesQuery struct {
+	Limit  uint64 `form:"limit" binding:"max=100"`
+	Offset uint64 `form:"offset"`
+}
+
+type Deliveries struct {
+	Deliveries []webhooks.Delivery `json:"deliveries"`
+}
+
+// CreateWebhook godoc
+// @Summary Создание вебхука
+// @Schemes
+// @Description Подписывает URL на события активного рабочего пространства: task.created, task.updated, comment.created, member.added
+// @Description Секрет возвращается только в ответе на создание. Каждый запрос подписан заголовком X-Webhook-Signature: sha256=HMAC-SHA256(секрет, "<X-Webhook-Timestamp>.<тело>")
+// @Description Неудачные доставки повторяются с экспоненциальной задержкой, после нескольких проваленных доставок подряд вебхук отключается
+// @Description Доступно владельцу и администраторам пространства
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param X-Workspace-ID header int true "Активное рабочее пространство"
+// @Param data body CreateWebhook true "Входные параметры"
+// @Success 200 {object} webhooks.Webhook
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 403 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/workspace/webhooks [post]
+func (api *Api) CreateWebhook(ctx *gin.Context) {
+	actor, err := popMemberFromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var req CreateWebhook
+	if err := api.bindJSON(ctx, &req); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	created, err := api.webhooks.Create(ctx, actor, req.URL, req.EventTypes)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, created)
+}
+
+// ListWebhooks godoc
+// @Summary Список вебхуков
+// @Schemes
+// @Description Возвращает вебхуки активного рабочего пространства без секретов
+// @Tags webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param X-Workspace-ID header int true "Активное рабочее пространство"
+// @Success 200 {object} Webhooks
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 403 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/workspace/webhooks [get]
+func (api *Api) ListWebhooks(ctx *gin.Context) {
+	actor, err := popMemberFromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	list, err := api.webhooks.List(ctx, actor)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Webhooks{Webhooks: list})
+}
+
+// DeleteWebhook godoc
+// @Summary Удаление вебхука
+// @Schemes
+// @Tags webhooks
+// @Security ApiKeyAuth
+// @Param X-Workspace-ID header int true "Активное рабочее пространство"
+// @Param webhook_id path int true "Идентификатор вебхука"
+// @Success 200
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 403 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/workspace/webhooks/{webhook_id} [delete]
+func (api *Api) DeleteWebhook(ctx *gin.Context) {
+	actor, err := popMemberFromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var uri WebhookURI
+	if err := bindURI(ctx, &uri); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	err = api.webhooks.Delete(ctx, actor, uri.ID)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.AbortWithStatus(http.StatusOK)
+}
+
+// EnableWebhook godoc
+// @Summary Включение вебхука
+// @Schemes
+// @Description Снова включает вебхук, отключённый из-за ошибок доставки, и сбрасывает счётчик ошибок
+// @Tags webhooks
+// @Security ApiKeyAuth
+// @Param X-Workspace-ID header int true "Активное рабочее пространство"
+// @Param webhook_id path int true "Идентификатор вебхука"
+// @Success 200
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 403 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/workspace/webhooks/{webhook_id}/enable [post]
+func (api *Api) EnableWebhook(ctx *gin.Context) {
+	actor, err := popMemberFromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var uri WebhookURI
+	if err := bindURI(ctx, &uri); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	err = api.webhooks.Enable(ctx, actor, uri.ID)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.AbortWithStatus(http.StatusOK)
+}
+
+// ListDeliveries godoc
+// @Summary Журнал доставок вебхука
+// @Schemes
+// @Description Возвращает доставки вебхука, начиная с новых, со статусом ответа и последней ошибкой
+// @Tags webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param X-Workspace-ID header int true "Активное рабочее пространство"
+// @Param webhook_id path int true "Идентификатор вебхука"
+// @Param limit query int false "Количество, по умолчанию 20, не больше 100"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} Deliveries
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 403 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/workspace/webhooks/{webhook_id}/deliveries [get]
+func (api *Api) ListDeliveries(ctx *gin.Context) {
+	actor, err := popMemberFromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var uri WebhookURI
+	if err := bindURI(ctx, &uri); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var query DeliveriesQuery
+	if err := bindQuery(ctx, &query); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	list, err := api.webhooks.Deliveries(ctx, actor, uri.ID, query.Limit, query.Offset)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Deliveries{Deliveries: list})
+}
+
+// ReplayDelivery godoc
+// @Summary Повторная отправка доставки
+// @Schemes
+// @Description Ставит в очередь новую доставку с тем же содержимым
+// @Tags webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param X-Workspace-ID header int true "Активное рабочее пространство"
+// @Param webhook_id path int true "Идентификатор вебхука"
+// @Param delivery_id path int true "Идентификатор доставки"
+// @Success 200 {object} webhooks.Delivery
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 403 {object} Error
+// @Failure 404 {object} Error
+// @Failure 409 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/workspace/webhooks/{webhook_id}/deliveries/{delivery_id}/replay [post]
+func (api *Api) ReplayDelivery(ctx *gin.Context) {
+	actor, err := popMemberFromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var uri DeliveryURI
+	if err := bindURI(ctx, &uri); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	delivery, err := api.webhooks.Replay(ctx, actor, uri.WebhookID, uri.ID)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, delivery)
+}
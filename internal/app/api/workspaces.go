@@ -0,0 +1,207 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"task-manager-backend/internal/app/errs"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/models/workspaces"
+	"task-manager-backend/internal/app/service/workspace"
+)
+
+const (
+	workspaceHeader  = "X-Workspace-ID"
+	memberContextKey = "workspace_member"
+)
+
+var invalidWorkspaceID = errs.Validation(errs.FieldError{
+	Field:   workspaceHeader,
+	Code:    "invalid_workspace_id",
+	Message: "Must be a workspace ID",
+})
+
+type CreateWorkspace struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type Workspaces struct {
+	Workspaces []workspaces.Workspace `json:"workspaces"`
+}
+
+type AddMember struct {
+	Email users.Email     `json:"email" binding:"required,email"`
+	Role  workspaces.Role `json:"role" binding:"required,enum"`
+}
+
+type Members struct {
+	Members []workspaces.Member `json:"members"`
+}
+
+// CreateWorkspace godoc
+// @Summary Создание рабочего пространства
+// @Schemes
+// @Description Создаёт рабочее пространство, создатель становится его владельцем
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body CreateWorkspace true "Входные параметры"
+// @Success 200 {object} workspaces.Workspace
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/workspaces [post]
+func (api *Api) CreateWorkspace(ctx *gin.Context) {
+	userID, err := popUserIDfromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var req CreateWorkspace
+	if err := api.bindJSON(ctx, &req); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	created, err := api.workspaces.Create(ctx, userID, req.Name)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, created)
+}
+
+// ListWorkspaces godoc
+// @Summary Список рабочих пространств
+// @Schemes
+// @Description Возвращает рабочие пространства, в которых состоит пользователь
+// @Tags workspaces
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} Workspaces
+// @Failure 401 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/workspaces [get]
+func (api *Api) ListWorkspaces(ctx *gin.Context) {
+	userID, err := popUserIDfromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	list, err := api.workspaces.List(ctx, userID)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Workspaces{Workspaces: list})
+}
+
+// ListMembers godoc
+// @Summary Участники рабочего пространства
+// @Schemes
+// @Description Возвращает участников активного рабочего пространства
+// @Tags workspaces
+// @Produce json
+// @Security ApiKeyAuth
+// @Param X-Workspace-ID header int true "Активное рабочее пространство"
+// @Success 200 {object} Members
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 403 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/workspace/members [get]
+func (api *Api) ListMembers(ctx *gin.Context) {
+	member, err := popMemberFromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	members, err := api.workspaces.Members(ctx, member)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Members{Members: members})
+}
+
+// AddMember godoc
+// @Summary Добавление участника
+// @Schemes
+// @Description Добавляет пользователя в активное рабочее пространство
+// @Description Доступно владельцу и администраторам пространства
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param X-Workspace-ID header int true "Активное рабочее пространство"
+// @Param data body AddMember true "Входные параметры"
+// @Success 200 {object} workspaces.Member
+// @Failure 400 {object} Error
+// @Failure 401 {object} Error
+// @Failure 403 {object} Error
+// @Failure 404 {object} Error
+// @Failure 500 {object} Error
+// @Router /v1/workspace/members [post]
+func (api *Api) AddMember(ctx *gin.Context) {
+	actor, err := popMemberFromContext(ctx)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	var req AddMember
+	if err := api.bindJSON(ctx, &req); err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	member, err := api.workspaces.AddMember(ctx, actor, req.Email, req.Role)
+	if err != nil {
+		fail(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+// WorkspaceMW resolves the active workspace from the X-Workspace-ID header
+// and rejects callers that are not its members. It must run after AuthMW.
+func (api *Api) WorkspaceMW() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := popUserIDfromContext(ctx)
+		if err != nil {
+			fail(ctx, err)
+			return
+		}
+
+		workspaceID, err := strconv.ParseUint(ctx.GetHeader(workspaceHeader), 10, 64)
+		if err != nil {
+			fail(ctx, invalidWorkspaceID)
+			return
+		}
+
+		member, err := api.workspaces.Member(ctx, workspaces.ID(workspaceID), userID)
+		if err != nil {
+			fail(ctx, err)
+			return
+		}
+
+		ctx.Set(memberContextKey, member)
+	}
+}
+
+func popMemberFromContext(ctx *gin.Context) (workspaces.Member, error) {
+	member, ok := ctx.Get(memberContextKey)
+	if !ok {
+		return workspaces.Member{}, workspace.NotMember
+	}
+
+	return member.(workspaces.Member), nil
+}
@@ -3,26 +3,73 @@ package config
 import (
 	"fmt"
 	"gopkg.in/yaml.v3"
+	"io"
 	"os"
 	"time"
 )
 
 type Api struct {
-	PORT string `yaml:"port"`
-	HOST string `yaml:"host"`
-	Auth `yaml:"auth"`
+	PORT              string        `yaml:"port"`
+	HOST              string        `yaml:"host"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	// WriteTimeout also ends event streams, so it is off by default.
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// MaxBodyBytes limits JSON request bodies. Avatar uploads have their
+	// own limit.
+	MaxBodyBytes int64 `yaml:"max_body_bytes"`
+	// InternalAddr is the host:port of the listener for operators, which
+	// serves /metrics and must not be reachable from the internet. A scraper
+	// in another container needs it on 0.0.0.0 with the port left
+	// unpublished. Empty turns it off.
+	InternalAddr string `yaml:"internal_addr"`
+	Auth         `yaml:"auth"`
 }
 
 type Auth struct {
-	EnableAuth bool          `yaml:"enable_auth"`
-	TokenTTL   time.Duration `yaml:"token_ttl"`
-	SignKey    string        `yaml:"sign_key"`
+	EnableAuth     bool           `yaml:"enable_auth"`
+	TokenTTL       time.Duration  `yaml:"token_ttl"`
+	SignKey        string         `yaml:"sign_key" secret:"true"`
+	UnconfirmedTTL time.Duration  `yaml:"unconfirmed_ttl"`
+	PasswordPolicy PasswordPolicy `yaml:"password_policy"`
+	PasswordHash   PasswordHash   `yaml:"password_hash"`
+	Registration   Registration   `yaml:"registration"`
+}
+
+type Registration struct {
+	Mode           string   `yaml:"mode"`
+	AllowedDomains []string `yaml:"allowed_domains"`
+	DeniedDomains  []string `yaml:"denied_domains"`
+}
+
+type PasswordHash struct {
+	Algorithm         string `yaml:"algorithm"`
+	BcryptCost        int    `yaml:"bcrypt_cost"`
+	Argon2Memory      uint32 `yaml:"argon2_memory"`
+	Argon2Iterations  uint32 `yaml:"argon2_iterations"`
+	Argon2Parallelism uint8  `yaml:"argon2_parallelism"`
+}
+
+type PasswordPolicy struct {
+	MinLength      int    `yaml:"min_length"`
+	MaxLength      int    `yaml:"max_length"`
+	RequireDigit   bool   `yaml:"require_digit"`
+	RequireLetter  bool   `yaml:"require_letter"`
+	RequireUpper   bool   `yaml:"require_upper"`
+	RequireSymbol  bool   `yaml:"require_symbol"`
+	AllowUnicode   bool   `yaml:"allow_unicode"`
+	MinStrength    int    `yaml:"min_strength"`
+	BreachedCorpus string `yaml:"breached_corpus"`
 }
 
 func (api *Api) GetAddr() string {
 	return fmt.Sprintf("%s:%s", api.HOST, api.PORT)
 }
 
+// PostgresDSN must name a role that is neither a superuser nor BYPASSRLS,
+// normally task_manager, or row level security does not isolate tenants.
 type PostgresDSN string
 
 func (p PostgresDSN) String() string {
@@ -31,39 +78,161 @@ func (p PostgresDSN) String() string {
 
 type ServiceConfiguration struct {
 	Api                `yaml:"api"`
-	PostgresDSN        `yaml:"postgres_dsn"`
+	PostgresDSN        `yaml:"postgres_dsn" secret:"true"`
 	RedisConfiguration `yaml:"redis_configuration"`
 	Mail               `yaml:"mail"`
+	Avatars            `yaml:"avatars"`
+	Webhooks           Webhooks   `yaml:"webhooks"`
+	Health             Health     `yaml:"health"`
+	Tracing            Tracing    `yaml:"tracing"`
+	Log                Log        `yaml:"log"`
+	PublicURL          string     `yaml:"public_url"`
+	CORS               CORS       `yaml:"cors" reload:"true"`
+	RateLimits         RateLimits `yaml:"rate_limits" reload:"true"`
+}
+
+type CORS struct {
+	// AllowedOrigins limits cross-origin requests to these origins. Empty
+	// allows any origin.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+}
+
+type RateLimits struct {
+	ConfirmResend RateLimit `yaml:"confirm_resend"`
+	Restore       RateLimit `yaml:"restore"`
+}
+
+// RateLimit allows Limit requests per Window.
+type RateLimit struct {
+	Limit  int64         `yaml:"limit"`
+	Window time.Duration `yaml:"window"`
+}
+
+type Webhooks struct {
+	// AllowedNetworks lists CIDRs webhooks may be delivered to although they
+	// are loopback, private or link-local, e.g. for receivers inside the
+	// cluster. Empty allows public addresses only.
+	AllowedNetworks []string `yaml:"allowed_networks"`
+}
+
+type Health struct {
+	// StartupTimeout is how long Postgres and Redis are retried at startup
+	// before the service starts without them.
+	StartupTimeout time.Duration `yaml:"startup_timeout"`
+	CheckTimeout   time.Duration `yaml:"check_timeout"`
+	// DrainDelay is how long /readyz reports not ready before the server
+	// stops accepting connections, so load balancers can react.
+	DrainDelay time.Duration `yaml:"drain_delay"`
+}
+
+type Tracing struct {
+	// Exporter is none, stdout (for local use) or otlp.
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the host:port of the OTLP/HTTP collector.
+	Endpoint    string  `yaml:"endpoint"`
+	Insecure    bool    `yaml:"insecure"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+	ServiceName string  `yaml:"service_name"`
+}
+
+type Log struct {
+	// Level is debug, info, warn or error.
+	Level string `yaml:"level" reload:"true"`
+	// Format is json, or console for reading logs in a terminal.
+	Format string `yaml:"format"`
+}
+
+type Avatars struct {
+	Dir       string `yaml:"dir"`
+	URLPrefix string `yaml:"url_prefix"`
 }
 
 type RedisConfiguration struct {
 	RedisAddr   string `yaml:"redis_addr"`
-	RedisPasswd string `yaml:"redis_passwd"`
+	RedisPasswd string `yaml:"redis_passwd" secret:"true"`
 }
 
 type Mail struct {
-	Addr  string `yaml:"addr"`
-	From  string `yaml:"from"`
-	Token string `yaml:"token"`
-	Host  string `yaml:"host"`
+	Transport string        `yaml:"transport"`
+	Addr      string        `yaml:"addr"`
+	From      string        `yaml:"from"`
+	FromName  string        `yaml:"from_name"`
+	Token     string        `yaml:"token" secret:"true"`
+	Host      string        `yaml:"host"`
+	TLS       string        `yaml:"tls"`
+	Timeout   time.Duration `yaml:"timeout"`
+	Dir       string        `yaml:"dir"`
+	// DevMailbox shows the mail kept by the memory transport on the internal
+	// listener. It is only for development.
+	DevMailbox bool `yaml:"dev_mailbox"`
+	// TemplatesDir overrides the embedded mail templates with the ones in
+	// <dir>/<locale>/*.tmpl.
+	TemplatesDir string `yaml:"templates_dir" reload:"true"`
 }
 
-func Load() ServiceConfiguration {
-	file, err := os.Open("config.yml")
-	if err != nil {
-		panic(err)
-	}
+// DefaultPath is read when no -config flag is given. Unlike an explicitly
+// given file it may be missing, so the service can run from defaults and
+// environment variables alone.
+const DefaultPath = "config.yml"
 
-	defer file.Close()
-	decoder := yaml.NewDecoder(file)
+// Default returns the settings used for anything the file and the
+// environment leave out. Settings whose defaults depend on other settings
+// are resolved by the services reading them.
+func Default() ServiceConfiguration {
 	var cfg ServiceConfiguration
-	err = decoder.Decode(&cfg)
-	if err != nil {
-		panic(err)
+	cfg.Api.PORT = "8080"
+	cfg.Api.ReadTimeout = 30 * time.Second
+	cfg.Api.ReadHeaderTimeout = 10 * time.Second
+	cfg.Api.IdleTimeout = 2 * time.Minute
+	cfg.Api.ShutdownTimeout = 20 * time.Second
+	cfg.Api.MaxBodyBytes = 1 << 20
+	cfg.Api.InternalAddr = "127.0.0.1:9090"
+	cfg.Api.Auth.TokenTTL = 15 * time.Minute
+	cfg.Api.Auth.PasswordPolicy = PasswordPolicy{
+		MinLength:     6,
+		MaxLength:     20,
+		RequireDigit:  true,
+		RequireLetter: true,
 	}
+	cfg.RedisAddr = "localhost:6379"
+	cfg.Health.StartupTimeout = 30 * time.Second
+	cfg.Health.CheckTimeout = 2 * time.Second
+	cfg.Tracing.Exporter = "none"
+	cfg.Tracing.SampleRatio = 1
+	cfg.Tracing.ServiceName = "task-manager-backend"
+	cfg.Log.Level = "info"
+	cfg.Log.Format = "json"
+	cfg.Mail.Transport = "smtp"
+	cfg.PublicURL = "http://localhost:5173"
+	cfg.RateLimits.ConfirmResend = RateLimit{Limit: 3, Window: time.Hour}
+	cfg.RateLimits.Restore = RateLimit{Limit: 2, Window: 24 * time.Hour}
 	return cfg
 }
 
-func NewConfig() ServiceConfiguration {
-	return Load()
+// Load builds the configuration from the defaults, the YAML file at path
+// and the environment, in that order, and validates the result. With
+// required unset a missing file is not an error.
+func Load(path string, required bool) (ServiceConfiguration, error) {
+	cfg := Default()
+
+	file, err := os.Open(path)
+	switch {
+	case err == nil:
+		defer file.Close()
+		decoder := yaml.NewDecoder(file)
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&cfg); err != nil && err != io.EOF {
+			return cfg, fmt.Errorf("config: cant parse %s: %w", path, err)
+		}
+	case os.IsNotExist(err) && !required:
+	default:
+		return cfg, fmt.Errorf("config: cant read %s: %w", path, err)
+	}
+
+	problems := applyEnv(&cfg, os.LookupEnv)
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return cfg, ValidationError(problems)
+	}
+	return cfg, nil
 }
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts the name of every environment variable read by Load.
+// The rest of the name is the YAML path of the setting in upper case with
+// underscores, e.g. TM_API_AUTH_SIGN_KEY for api.auth.sign_key. Appending
+// _FILE reads the value from the named file instead, which is how secrets
+// mounted by Docker or Kubernetes are passed.
+const EnvPrefix = "TM_"
+
+const fileSuffix = "_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setting is a leaf of the configuration tree. The secret and reload tags
+// apply to everything below the field they are set on.
+type setting struct {
+	path   string
+	env    string
+	secret bool
+	reload bool
+	value  reflect.Value
+}
+
+// settings lists the leaves of cfg in declaration order. Their values can be
+// set through the returned reflect.Values.
+func settings(cfg *ServiceConfiguration) []setting {
+	list := make([]setting, 0)
+	walk(reflect.ValueOf(cfg).Elem(), setting{}, nil, &list)
+	return list
+}
+
+func walk(value reflect.Value, parent setting, path []string, list *[]setting) {
+	if value.Kind() == reflect.Struct {
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			walk(value.Field(i), setting{
+				secret: parent.secret || field.Tag.Get("secret") == "true",
+				reload: parent.reload || field.Tag.Get("reload") == "true",
+			}, append(path[:len(path):len(path)], name), list)
+		}
+		return
+	}
+
+	*list = append(*list, setting{
+		path:   strings.Join(path, "."),
+		env:    EnvPrefix + strings.ToUpper(strings.Join(path, "_")),
+		secret: parent.secret,
+		reload: parent.reload,
+		value:  value,
+	})
+}
+
+// applyEnv overrides the settings present in the environment and returns a
+// problem for every value that cant be used.
+func applyEnv(cfg *ServiceConfiguration, lookup func(string) (string, bool)) []string {
+	problems := make([]string, 0)
+	for _, s := range settings(cfg) {
+		raw, ok := lookup(s.env)
+		fileName, fromFile := lookup(s.env + fileSuffix)
+
+		switch {
+		case ok && fromFile:
+			problems = append(problems, fmt.Sprintf("%s: both %s and %s are set", s.path, s.env, s.env+fileSuffix))
+			continue
+		case fromFile:
+			data, err := os.ReadFile(fileName)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: cant read %s: %v", s.path, s.env+fileSuffix, err))
+				continue
+			}
+			raw = strings.TrimRight(string(data), "\r\n")
+		case !ok:
+			continue
+		}
+
+		if err := setString(s.value, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s: %v", s.path, s.env, err))
+		}
+	}
+	return problems
+}
+
+func setString(value reflect.Value, raw string) error {
+	if value.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", value.Type())
+		}
+		items := make([]string, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"gopkg.in/yaml.v3"
+	"io"
+)
+
+const redacted = "***"
+
+// Redacted returns a copy of the configuration with the secrets masked.
+// Empty secrets stay empty, so it is still visible that one is missing.
+func (cfg ServiceConfiguration) Redacted() ServiceConfiguration {
+	for _, s := range settings(&cfg) {
+		if s.secret && !s.value.IsZero() {
+			s.value.SetString(redacted)
+		}
+	}
+	return cfg
+}
+
+// Print writes the effective configuration as YAML with the secrets masked.
+func (cfg ServiceConfiguration) Print(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg.Redacted()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
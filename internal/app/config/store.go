@@ -0,0 +1,182 @@
+package config
+
+import (
+	"context"
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// reloadDelay lets editors and config map updates finish writing before the
+// file is read.
+const reloadDelay = 200 * time.Millisecond
+
+// Store holds the live configuration. Settings tagged reload:"true" follow
+// the file and environment on SIGHUP or when the file changes; everything
+// else keeps its startup value until the next restart.
+type Store struct {
+	path string
+
+	current atomic.Pointer[ServiceConfiguration]
+
+	mu          sync.Mutex
+	checks      []func(ServiceConfiguration) error
+	subscribers []func(ServiceConfiguration)
+}
+
+func NewStore(cfg ServiceConfiguration, path string) *Store {
+	store := &Store{
+		path: path,
+	}
+	store.current.Store(&cfg)
+	return store
+}
+
+func (s *Store) Current() ServiceConfiguration {
+	return *s.current.Load()
+}
+
+// Check adds a validation a reloaded configuration must pass before it
+// replaces the current one. It is for components that can only tell whether
+// they can use a setting by trying, such as parsing templates.
+func (s *Store) Check(check func(ServiceConfiguration) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checks = append(s.checks, check)
+}
+
+// Subscribe calls fn with every configuration that replaced the current one.
+func (s *Store) Subscribe(fn func(ServiceConfiguration)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Reload reads the configuration again and swaps in its reloadable
+// settings. An invalid configuration is rejected as a whole and the current
+// one is kept. So is a missing file: it is gone for a moment while it is
+// being replaced, and a service started without one has nothing to reload.
+// The store is built before the logger, so the logger comes with the call.
+func (s *Store) Reload(logger *zap.Logger) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loaded, err := Load(s.path, true)
+	if err != nil {
+		return err
+	}
+
+	current := s.Current()
+	next := current
+	restart := make([]string, 0)
+
+	loadedSettings := settings(&loaded)
+	currentSettings := settings(&current)
+	for i, setting := range settings(&next) {
+		if reflect.DeepEqual(setting.value.Interface(), loadedSettings[i].value.Interface()) {
+			continue
+		}
+		if !setting.reload {
+			restart = append(restart, setting.path)
+			continue
+		}
+		setting.value.Set(loadedSettings[i].value)
+	}
+	if len(restart) > 0 {
+		logger.Warn("Config: Changes take effect after a restart", zap.Strings("settings", restart))
+	}
+
+	for _, check := range s.checks {
+		if err := check(next); err != nil {
+			return err
+		}
+	}
+
+	s.current.Store(&next)
+	for i, setting := range settings(&next) {
+		if setting.reload && !reflect.DeepEqual(setting.value.Interface(), currentSettings[i].value.Interface()) {
+			logger.Info("Config: Reloaded", zap.String("setting", setting.path))
+		}
+	}
+	for _, fn := range s.subscribers {
+		fn(next)
+	}
+	return nil
+}
+
+// WatchHook reloads the configuration on SIGHUP and whenever the config
+// file changes, for the lifetime of the app.
+func WatchHook(lifecycle fx.Lifecycle, store *Store, logger *zap.Logger) {
+	signals := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	var watcher *fsnotify.Watcher
+	lifecycle.Append(
+		fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				signal.Notify(signals, syscall.SIGHUP)
+
+				var err error
+				if watcher, err = fsnotify.NewWatcher(); err != nil {
+					return err
+				}
+				// The directory is watched rather than the file, so that
+				// replacing the file (as editors and config maps do) is
+				// noticed too.
+				if err = watcher.Add(filepath.Dir(store.path)); err != nil {
+					logger.Error("Config: Cant watch the config file", zap.String("path", store.path), zap.Error(err))
+				}
+
+				go store.watch(signals, watcher, logger, stop, done)
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				signal.Stop(signals)
+				close(stop)
+				select {
+				case <-done:
+				case <-ctx.Done():
+				}
+				return watcher.Close()
+			},
+		})
+}
+
+func (s *Store) watch(signals <-chan os.Signal, watcher *fsnotify.Watcher, logger *zap.Logger, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	name := filepath.Clean(s.path)
+	timer := time.NewTimer(0)
+	<-timer.C
+
+	for {
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-signals:
+			timer.Reset(0)
+		case event := <-watcher.Events:
+			if filepath.Clean(event.Name) == name && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				timer.Reset(reloadDelay)
+			}
+		case err := <-watcher.Errors:
+			logger.Error("Config: Watcher error", zap.Error(err))
+		case <-timer.C:
+			if err := s.Reload(logger); err != nil {
+				logger.Error("Config: Reload rejected, keeping the current configuration", zap.Error(err))
+			}
+		}
+	}
+}
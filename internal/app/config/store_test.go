@@ -0,0 +1,37 @@
+package config
+
+import (
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReloadKeepsConfigWhenFileIsMissing(t *testing.T) {
+	cfg := Default()
+	cfg.Log.Level = "debug"
+	store := NewStore(cfg, filepath.Join(t.TempDir(), "config.yml"))
+
+	if err := store.Reload(zap.NewNop()); err == nil {
+		t.Fatal("reload without a file was accepted")
+	}
+	if got := store.Current().Log.Level; got != "debug" {
+		t.Fatalf("log level is %q after a failed reload, want the current one", got)
+	}
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte("log:\n  level: loud\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Default()
+	store := NewStore(cfg, path)
+
+	if err := store.Reload(zap.NewNop()); err == nil {
+		t.Fatal("invalid configuration was accepted")
+	}
+	if got := store.Current().Log.Level; got != cfg.Log.Level {
+		t.Fatalf("log level is %q after a rejected reload, want %q", got, cfg.Log.Level)
+	}
+}
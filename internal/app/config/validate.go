@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError lists every problem found in the configuration, so they
+// can all be fixed in one go.
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e, "\n  - ")
+}
+
+// Accepted values of the enumerated settings. They mirror the constants of
+// the services reading them, which cant be imported from here.
+var (
+	mailTransports    = []string{"smtp", "file", "log", "memory"}
+	mailTLSModes      = []string{"", "starttls", "implicit", "none"}
+	registrationModes = []string{"", "open", "closed", "invite"}
+	hashAlgorithms    = []string{"", "argon2id", "bcrypt"}
+	tracingExporters  = []string{"none", "stdout", "otlp"}
+	logLevels         = []string{"debug", "info", "warn", "error"}
+	logFormats        = []string{"json", "console"}
+)
+
+// Validate checks the settings the services cant start without.
+func (cfg ServiceConfiguration) Validate() error {
+	if problems := cfg.validate(); len(problems) > 0 {
+		return ValidationError(problems)
+	}
+	return nil
+}
+
+func (cfg ServiceConfiguration) validate() []string {
+	problems := make([]string, 0)
+	problem := func(path, format string, args ...interface{}) {
+		problems = append(problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if port, err := strconv.Atoi(cfg.Api.PORT); err != nil || port < 1 || port > 65535 {
+		problem("api.port", "must be a port number, got %q", cfg.Api.PORT)
+	}
+	for _, timeout := range []struct {
+		path  string
+		value time.Duration
+	}{
+		{"api.read_timeout", cfg.Api.ReadTimeout},
+		{"api.read_header_timeout", cfg.Api.ReadHeaderTimeout},
+		{"api.write_timeout", cfg.Api.WriteTimeout},
+		{"api.idle_timeout", cfg.Api.IdleTimeout},
+	} {
+		if timeout.value < 0 {
+			problem(timeout.path, "cant be negative, got %s", timeout.value)
+		}
+	}
+	if cfg.Api.ShutdownTimeout <= 0 {
+		problem("api.shutdown_timeout", "must be positive, got %s", cfg.Api.ShutdownTimeout)
+	}
+	if cfg.Api.MaxBodyBytes <= 0 {
+		problem("api.max_body_bytes", "must be positive, got %d", cfg.Api.MaxBodyBytes)
+	}
+	if cfg.Api.InternalAddr != "" {
+		if _, port, err := net.SplitHostPort(cfg.Api.InternalAddr); err != nil || port == "" {
+			problem("api.internal_addr", "must be host:port, got %q", cfg.Api.InternalAddr)
+		}
+	}
+	if cfg.Api.Auth.SignKey == "" {
+		problem("api.auth.sign_key", "is required")
+	}
+	if cfg.Api.Auth.TokenTTL <= 0 {
+		problem("api.auth.token_ttl", "must be positive, got %s", cfg.Api.Auth.TokenTTL)
+	}
+	if cfg.Api.Auth.UnconfirmedTTL < 0 {
+		problem("api.auth.unconfirmed_ttl", "cant be negative, got %s", cfg.Api.Auth.UnconfirmedTTL)
+	}
+	if !oneOf(cfg.Api.Auth.Registration.Mode, registrationModes) {
+		problem("api.auth.registration.mode", "must be one of %s, got %q", list(registrationModes), cfg.Api.Auth.Registration.Mode)
+	}
+	if !oneOf(cfg.Api.Auth.PasswordHash.Algorithm, hashAlgorithms) {
+		problem("api.auth.password_hash.algorithm", "must be one of %s, got %q", list(hashAlgorithms), cfg.Api.Auth.PasswordHash.Algorithm)
+	}
+	// 0 picks the default cost, otherwise bcrypt accepts 4 to 31.
+	if cost := cfg.Api.Auth.PasswordHash.BcryptCost; cost != 0 && (cost < 4 || cost > 31) {
+		problem("api.auth.password_hash.bcrypt_cost", "must be between 4 and 31, got %d", cost)
+	}
+
+	if cfg.Health.StartupTimeout < 0 {
+		problem("health.startup_timeout", "cant be negative, got %s", cfg.Health.StartupTimeout)
+	}
+	if cfg.Health.CheckTimeout <= 0 {
+		problem("health.check_timeout", "must be positive, got %s", cfg.Health.CheckTimeout)
+	}
+	if cfg.Health.DrainDelay < 0 {
+		problem("health.drain_delay", "cant be negative, got %s", cfg.Health.DrainDelay)
+	}
+
+	if !oneOf(cfg.Tracing.Exporter, tracingExporters) {
+		problem("tracing.exporter", "must be one of %s, got %q", list(tracingExporters), cfg.Tracing.Exporter)
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		problem("tracing.sample_ratio", "must be between 0 and 1, got %g", cfg.Tracing.SampleRatio)
+	}
+
+	if !oneOf(cfg.Log.Level, logLevels) {
+		problem("log.level", "must be one of %s, got %q", list(logLevels), cfg.Log.Level)
+	}
+	if !oneOf(cfg.Log.Format, logFormats) {
+		problem("log.format", "must be one of %s, got %q", list(logFormats), cfg.Log.Format)
+	}
+
+	if cfg.PostgresDSN == "" {
+		problem("postgres_dsn", "is required")
+	}
+	if cfg.RedisAddr == "" {
+		problem("redis_configuration.redis_addr", "is required")
+	}
+
+	if !oneOf(cfg.Mail.Transport, mailTransports) {
+		problem("mail.transport", "must be one of %s, got %q", list(mailTransports), cfg.Mail.Transport)
+	}
+	if !oneOf(cfg.Mail.TLS, mailTLSModes) {
+		problem("mail.tls", "must be one of %s, got %q", list(mailTLSModes), cfg.Mail.TLS)
+	}
+	if cfg.Mail.Transport == "smtp" {
+		if cfg.Mail.Addr == "" {
+			problem("mail.addr", "is required for the smtp transport")
+		}
+		if cfg.Mail.From == "" {
+			problem("mail.from", "is required for the smtp transport")
+		}
+	}
+	if cfg.Mail.DevMailbox {
+		if cfg.Mail.Transport != "memory" {
+			problem("mail.dev_mailbox", "is only for development with the memory transport, got transport %q", cfg.Mail.Transport)
+		}
+		if cfg.Api.InternalAddr == "" {
+			problem("mail.dev_mailbox", "needs api.internal_addr to be served on")
+		}
+	}
+	if cfg.Mail.Timeout < 0 {
+		problem("mail.timeout", "cant be negative, got %s", cfg.Mail.Timeout)
+	}
+
+	checkRateLimit := func(path string, limit RateLimit) {
+		if limit.Limit <= 0 || limit.Window <= 0 {
+			problem(path, "limit and window must be positive, got %d per %s", limit.Limit, limit.Window)
+		}
+	}
+	checkRateLimit("rate_limits.confirm_resend", cfg.RateLimits.ConfirmResend)
+	checkRateLimit("rate_limits.restore", cfg.RateLimits.Restore)
+	for _, origin := range cfg.CORS.AllowedOrigins {
+		if u, err := url.Parse(origin); err != nil || u.Host == "" || u.Path != "" {
+			problem("cors.allowed_origins", "%q is not an origin like https://example.com", origin)
+		}
+	}
+
+	for _, network := range cfg.Webhooks.AllowedNetworks {
+		if _, _, err := net.ParseCIDR(network); err != nil {
+			problem("webhooks.allowed_networks", "%q is not a CIDR like 10.0.0.0/8", network)
+		}
+	}
+
+	if publicURL, err := url.Parse(cfg.PublicURL); err != nil || publicURL.Host == "" ||
+		(publicURL.Scheme != "http" && publicURL.Scheme != "https") {
+		problem("public_url", "must be an absolute http(s) URL, got %q", cfg.PublicURL)
+	}
+
+	return problems
+}
+
+func oneOf(value string, allowed []string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+func list(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			quoted = append(quoted, strconv.Quote(v))
+		}
+	}
+	return strings.Join(quoted, ", ")
+}
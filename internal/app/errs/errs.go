@@ -0,0 +1,89 @@
+package errs
+
+import (
+	"errors"
+)
+
+// Kind is the class of a failure. The API derives the response status from
+// it, so services need not know about HTTP.
+type Kind int
+
+const (
+	Internal Kind = iota
+	Invalid
+	Unauthorized
+	Forbidden
+	NotFound
+	Conflict
+	TooLarge
+	TooManyRequests
+)
+
+// Codes shared by several services.
+const (
+	CodeInternal   = "internal_error"
+	CodeInvalid    = "invalid_data"
+	CodeValidation = "validation_failed"
+)
+
+// Error is a domain error. Code is stable and meant for clients to act on;
+// Message is for people and may change.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	// Fields lists the request fields that failed validation, if any.
+	Fields []FieldError
+}
+
+// FieldError is a problem with one request field. Params are the values
+// the message refers to, such as a length limit.
+type FieldError struct {
+	Field   string            `json:"field"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Params  map[string]string `json:"params,omitempty"`
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Validation returns an error listing every failing field.
+func Validation(fields ...FieldError) *Error {
+	return &Error{
+		Kind:    Invalid,
+		Code:    CodeValidation,
+		Message: "Invalid data",
+		Fields:  fields,
+	}
+}
+
+// Field describes err as a problem with the named request field. Errors
+// with Code and Params methods keep their code and params.
+func Field(field string, err error) FieldError {
+	fieldErr := FieldError{Field: field, Code: CodeInvalid, Message: err.Error()}
+	var coder interface{ Code() string }
+	if errors.As(err, &coder) {
+		fieldErr.Code = coder.Code()
+	} else if e, ok := As(err); ok {
+		fieldErr.Code = e.Code
+	}
+	var params interface{ Params() map[string]string }
+	if errors.As(err, &params) {
+		fieldErr.Params = params.Params()
+	}
+	return fieldErr
+}
+
+// As returns the domain error in err's chain. Errors without one are
+// internal.
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
@@ -0,0 +1,180 @@
+package errs
+
+import (
+	"sort"
+	"strings"
+	"task-manager-backend/internal/app/models/users"
+)
+
+// messages are the texts of the error codes by locale. {name} is replaced
+// with the param of that name.
+var messages = map[string]map[string]string{
+	"ru": {
+		CodeInternal:   "Внутренняя ошибка сервера",
+		CodeInvalid:    "Некорректные данные",
+		CodeValidation: "Некорректные данные",
+
+		"malformed_body":       "Тело запроса не является корректным JSON",
+		"not_found":            "Не найдено",
+		"invalid_type":         "Ожидается значение типа {type}",
+		"required":             "Обязательное поле",
+		"invalid_workspace_id": "Ожидается идентификатор рабочего пространства",
+		"unknown_field":        "Неизвестное поле",
+		"body_too_large":       "Слишком большое тело запроса",
+		"invalid_id":           "Ожидается положительный идентификатор",
+		"invalid_value":        "Недопустимое значение",
+		"not_one_of":           "Допустимые значения: {values}",
+		"too_short":            "Должно быть не короче {min} символов",
+		"too_long":             "Должно быть не длиннее {max} символов",
+		"too_few":              "Должно быть не меньше {min} элементов",
+		"too_many":             "Должно быть не больше {max} элементов",
+		"too_small":            "Должно быть не меньше {min}",
+		"too_large":            "Должно быть не больше {max}",
+		"invalid_url":          "Некорректный URL",
+		"invalid_timezone":     "Неизвестный часовой пояс",
+		"invalid_locale":       "Некорректный код языка",
+
+		"invalid_email":            "Некорректный email",
+		"email_taken":              "Этот email уже зарегистрирован",
+		"invalid_credentials":      "Неверный логин или пароль",
+		"email_not_found":          "Этот email не зарегистрирован",
+		"invalid_refresh_token":    "Недействительный refresh токен",
+		"invalid_token":            "Ссылка недействительна или устарела",
+		"unauthenticated":          "Требуется авторизация",
+		"email_not_confirmed":      "Регистрация не подтверждена",
+		"same_password":            "Новый пароль совпадает со старым",
+		"too_many_requests":        "Слишком много запросов, попробуйте позже",
+		"registration_closed":      "Регистрация закрыта",
+		"invite_required":          "Регистрация возможна только по приглашению",
+		"email_domain_not_allowed": "Регистрация с этого почтового домена запрещена",
+		"invalid_invite":           "Недействительное приглашение",
+		"not_allowed":              "Недостаточно прав",
+
+		"password_too_short":   "Пароль должен быть не короче {min} символов",
+		"password_too_long":    "Пароль должен быть не длиннее {max} символов",
+		"password_no_digit":    "Пароль должен содержать цифру",
+		"password_no_letter":   "Пароль должен содержать букву",
+		"password_no_upper":    "Пароль должен содержать заглавную букву",
+		"password_no_symbol":   "Пароль должен содержать специальный символ",
+		"password_bad_symbols": "Пароль содержит недопустимые символы",
+		"password_too_weak":    "Пароль слишком легко подобрать",
+		"password_breached":    "Пароль встречается в утечках данных",
+
+		"not_workspace_member":   "Вы не участник этого рабочего пространства",
+		"user_not_found":         "Пользователь не найден",
+		"already_member":         "Пользователь уже участник этого рабочего пространства",
+		"invalid_image":          "Неподдерживаемое или повреждённое изображение",
+		"avatar_too_large":       "Слишком большой аватар",
+		"notification_not_found": "Уведомление не найдено",
+		"webhook_not_found":      "Вебхук не найден",
+		"webhook_disabled":       "Вебхук отключён",
+		"job_not_found":          "Задание не найдено",
+		"job_not_dead":           "Повторить можно только упавшие задания",
+		"job_key_pending":        "Задание с тем же ключом уже ждёт выполнения",
+		"template_not_found":     "Неизвестный шаблон письма",
+	},
+	"en": {
+		CodeInternal:   "Internal server error",
+		CodeInvalid:    "Invalid data",
+		CodeValidation: "Invalid data",
+
+		"malformed_body":       "Request body is not valid JSON",
+		"not_found":            "Not found",
+		"invalid_type":         "Must be {type}",
+		"required":             "Is required",
+		"invalid_workspace_id": "Must be a workspace ID",
+		"unknown_field":        "Unknown field",
+		"body_too_large":       "Request body is too large",
+		"invalid_id":           "Must be a positive ID",
+		"invalid_value":        "Invalid value",
+		"not_one_of":           "Must be one of {values}",
+		"too_short":            "Must be at least {min} characters long",
+		"too_long":             "Must be at most {max} characters long",
+		"too_few":              "Must have at least {min} items",
+		"too_many":             "Must have at most {max} items",
+		"too_small":            "Must be at least {min}",
+		"too_large":            "Must be at most {max}",
+		"invalid_url":          "Invalid URL",
+		"invalid_timezone":     "Unknown time zone",
+		"invalid_locale":       "Invalid language code",
+
+		"invalid_email":            "Invalid email",
+		"email_taken":              "This email was registered before",
+		"invalid_credentials":      "Incorrect login or password",
+		"email_not_found":          "This email is not registered",
+		"invalid_refresh_token":    "Invalid refresh token",
+		"invalid_token":            "Invalid token",
+		"unauthenticated":          "Authentication required",
+		"email_not_confirmed":      "Registration has not been confirmed",
+		"same_password":            "Same password",
+		"too_many_requests":        "Too many requests, try again later",
+		"registration_closed":      "Registration is closed",
+		"invite_required":          "Registration requires an invitation",
+		"email_domain_not_allowed": "Registration from this email domain is not allowed",
+		"invalid_invite":           "Invalid invitation",
+		"not_allowed":              "Not enough rights",
+
+		"password_too_short":   "Password must be at least {min} characters long",
+		"password_too_long":    "Password must be at most {max} characters long",
+		"password_no_digit":    "Password must contain a digit",
+		"password_no_letter":   "Password must contain a letter",
+		"password_no_upper":    "Password must contain an uppercase letter",
+		"password_no_symbol":   "Password must contain a special character",
+		"password_bad_symbols": "Password contains unsupported characters",
+		"password_too_weak":    "Password is too easy to guess",
+		"password_breached":    "Password has appeared in a data breach",
+
+		"not_workspace_member":   "You are not a member of this workspace",
+		"user_not_found":         "User not found",
+		"already_member":         "User is already a member of this workspace",
+		"invalid_image":          "Unsupported or corrupted image",
+		"avatar_too_large":       "Avatar is too large",
+		"notification_not_found": "Notification not found",
+		"webhook_not_found":      "Webhook not found",
+		"webhook_disabled":       "Webhook is disabled",
+		"job_not_found":          "Job not found",
+		"job_not_dead":           "Only dead jobs can be retried",
+		"job_key_pending":        "A job with the same key is already pending",
+		"template_not_found":     "Unknown mail template",
+	},
+}
+
+// Locales lists the locales errors are translated to, the default first.
+func Locales() []string {
+	others := make([]string, 0, len(messages))
+	for locale := range messages {
+		if locale != users.DefaultLocale {
+			others = append(others, locale)
+		}
+	}
+	sort.Strings(others)
+	return append([]string{users.DefaultLocale}, others...)
+}
+
+// Localize returns a copy of the error with its messages in the locale.
+// Codes missing from the catalogue keep their message.
+func (e *Error) Localize(locale string) *Error {
+	localized := *e
+	localized.Message = translate(locale, e.Code, e.Message, nil)
+	localized.Fields = make([]FieldError, len(e.Fields))
+	for i, field := range e.Fields {
+		field.Message = translate(locale, field.Code, field.Message, field.Params)
+		localized.Fields[i] = field
+	}
+	return &localized
+}
+
+func translate(locale, code, fallback string, params map[string]string) string {
+	texts, ok := messages[locale]
+	if !ok {
+		texts = messages[users.DefaultLocale]
+	}
+	text, ok := texts[code]
+	if !ok {
+		return fallback
+	}
+	for name, value := range params {
+		text = strings.ReplaceAll(text, "{"+name+"}", value)
+	}
+	return text
+}
@@ -0,0 +1,111 @@
+package errs
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// sourceCodes collects the codes of the errors made with New anywhere under
+// root, by code, with where each was made.
+func sourceCodes(t *testing.T, root string) map[string]string {
+	t.Helper()
+	shared := map[string]string{
+		"CodeInternal":   CodeInternal,
+		"CodeInvalid":    CodeInvalid,
+		"CodeValidation": CodeValidation,
+	}
+
+	codes := make(map[string]string)
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || len(call.Args) != 3 || !isNew(call.Fun, file.Name.Name) {
+				return true
+			}
+			where := fset.Position(call.Pos()).String()
+			switch arg := call.Args[1].(type) {
+			case *ast.BasicLit:
+				code, err := strconv.Unquote(arg.Value)
+				if err != nil {
+					t.Fatalf("%s: %v", where, err)
+				}
+				codes[code] = where
+			case *ast.SelectorExpr:
+				codes[shared[arg.Sel.Name]] = where
+			case *ast.Ident:
+				codes[shared[arg.Name]] = where
+			default:
+				t.Errorf("%s: the code is not a literal or a shared code", where)
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return codes
+}
+
+func isNew(fun ast.Expr, pkg string) bool {
+	switch fun := fun.(type) {
+	case *ast.SelectorExpr:
+		x, ok := fun.X.(*ast.Ident)
+		return ok && x.Name == "errs" && fun.Sel.Name == "New"
+	case *ast.Ident:
+		return pkg == "errs" && fun.Name == "New"
+	}
+	return false
+}
+
+// Every error a service can return must be translated, or clients get its
+// English message whatever language they asked for.
+func TestEveryCodeHasMessages(t *testing.T) {
+	codes := sourceCodes(t, "..")
+	if len(codes) == 0 {
+		t.Fatal("found no errors made with New")
+	}
+
+	for _, locale := range Locales() {
+		for code, where := range codes {
+			if code == "" {
+				t.Errorf("%s: unknown shared code", where)
+				continue
+			}
+			if _, ok := messages[locale][code]; !ok {
+				t.Errorf("%s: code %q has no %s message", where, code, locale)
+			}
+		}
+	}
+}
+
+func TestLocalesHaveTheSameCodes(t *testing.T) {
+	base := messages[Locales()[0]]
+	for _, locale := range Locales()[1:] {
+		for code := range base {
+			if _, ok := messages[locale][code]; !ok {
+				t.Errorf("code %q has no %s message", code, locale)
+			}
+		}
+		for code := range messages[locale] {
+			if _, ok := base[code]; !ok {
+				t.Errorf("code %q has a %s message only", code, locale)
+			}
+		}
+	}
+}
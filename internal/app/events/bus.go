@@ -0,0 +1,104 @@
+package events
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"go.uber.org/multierr"
+	"sync"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/models/workspaces"
+	"time"
+)
+
+type Type string
+
+const (
+	ConfirmationRequested    Type = "auth.confirmation_requested"
+	PasswordRestoreRequested Type = "auth.password_restore_requested"
+	UserInvited              Type = "auth.user_invited"
+
+	TaskCreated       Type = "task.created"
+	TaskUpdated       Type = "task.updated"
+	TaskMoved         Type = "task.moved"
+	TaskAssigned      Type = "task.assigned"
+	TaskStatusChanged Type = "task.status_changed"
+	TaskDueSoon       Type = "task.due_soon"
+	CommentCreated    Type = "comment.created"
+	UserMentioned     Type = "comment.mentioned"
+	BoardUpdated      Type = "board.updated"
+	MemberAdded       Type = "member.added"
+)
+
+// Payload keys shared by publishers and handlers.
+const (
+	PayloadEmail  = "email"
+	PayloadLocale = "locale"
+	PayloadToken  = "token"
+	PayloadUser   = "user_id"
+	PayloadRole   = "role"
+)
+
+type Event struct {
+	ID          string            `json:"id"`
+	Type        Type              `json:"type"`
+	WorkspaceID workspaces.ID     `json:"workspace_id,omitempty"`
+	ActorID     users.ID          `json:"actor_id,omitempty"`
+	Recipients  []users.ID        `json:"recipients,omitempty"`
+	Payload     map[string]string `json:"payload,omitempty"`
+	OccurredAt  time.Time         `json:"occurred_at"`
+}
+
+func NewEvent(eventType Type, actorID users.ID, recipients []users.ID, payload map[string]string) Event {
+	return Event{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		ActorID:    actorID,
+		Recipients: recipients,
+		Payload:    payload,
+		OccurredAt: time.Now(),
+	}
+}
+
+// Record is an event together with the ID it was given when it was appended
+// to a persistent stream.
+type Record struct {
+	ID    string `json:"id"`
+	Event Event  `json:"event"`
+}
+
+type Handler func(context.Context, Event) error
+
+// Bus is an in-process publish/subscribe hub. Publishers do not know who
+// reacts to an event, which keeps side effects such as mail and
+// notifications out of the domain services.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[Type][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[Type][]Handler),
+	}
+}
+
+func (b *Bus) Subscribe(eventType Type, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish runs every handler of the event type synchronously. A failing
+// handler does not stop the others; all their errors are returned combined.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	handlers := b.handlers[event.Type]
+	b.mu.RUnlock()
+
+	var err error
+	for _, handler := range handlers {
+		err = multierr.Append(err, handler(ctx, event))
+	}
+	return err
+}
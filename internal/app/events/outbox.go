@@ -0,0 +1,94 @@
+package events
+
+import (
+	"context"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"task-manager-backend/internal/app/worker"
+	"time"
+)
+
+const (
+	relayInterval     = 500 * time.Millisecond
+	relayBatch        = 100
+	retryBase         = 5 * time.Second
+	retryMax          = time.Hour
+	pruneInterval     = time.Hour
+	ProcessedRetained = 7 * 24 * time.Hour
+)
+
+var retry = worker.Backoff{Base: retryBase, Max: retryMax}
+
+// OutboxRepository is the storage side of the transactional outbox: events
+// recorded together with the changes they describe, and the events each
+// consumer has already handled.
+type OutboxRepository interface {
+	RelayOutbox(context.Context, uint64, func(context.Context, Event) error, func(int) time.Duration) (int, error)
+	EventProcessed(context.Context, string, string) (bool, error)
+	MarkEventProcessed(context.Context, string, string) error
+	DeleteProcessedEvents(context.Context, time.Time) (int64, error)
+}
+
+// Once makes the handler idempotent. Outbox events are delivered at least
+// once, and an event is published again when any of its handlers fails, so
+// every handler with side effects remembers the events it has handled under
+// its consumer name and skips them afterwards.
+func Once(consumer string, repository OutboxRepository, handler Handler) Handler {
+	return func(ctx context.Context, event Event) error {
+		if event.ID == "" {
+			return handler(ctx, event)
+		}
+
+		processed, err := repository.EventProcessed(ctx, consumer, event.ID)
+		if err != nil {
+			return err
+		}
+		if processed {
+			return nil
+		}
+
+		if err := handler(ctx, event); err != nil {
+			return err
+		}
+		return repository.MarkEventProcessed(ctx, consumer, event.ID)
+	}
+}
+
+// RelayHook publishes the outbox to the bus for the lifetime of the app.
+func RelayHook(lifecycle fx.Lifecycle, bus *Bus, repository OutboxRepository, logger *zap.Logger) {
+	worker.Hook(lifecycle, func(stop <-chan struct{}) {
+		relay(bus, repository, logger, stop)
+	})
+}
+
+func relay(bus *Bus, repository OutboxRepository, logger *zap.Logger, stop <-chan struct{}) {
+	ticker := time.NewTicker(relayInterval)
+	defer ticker.Stop()
+
+	var pruned time.Time
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx := context.Background()
+		for {
+			relayed, err := repository.RelayOutbox(ctx, relayBatch, bus.Publish, retry.Delay)
+			if err != nil {
+				logger.Error("Outbox: Cant relay events", zap.Error(err))
+			}
+			if err != nil || relayed < relayBatch {
+				break
+			}
+		}
+
+		if time.Since(pruned) > pruneInterval {
+			if _, err := repository.DeleteProcessedEvents(ctx, time.Now().Add(-ProcessedRetained)); err != nil {
+				logger.Error("Outbox: Cant prune processed events", zap.Error(err))
+			}
+			pruned = time.Now()
+		}
+	}
+}
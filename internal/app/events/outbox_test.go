@@ -0,0 +1,110 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// memoryOutbox remembers processed events in a map. Relaying is left to the
+// repository's integration tests.
+type memoryOutbox struct {
+	OutboxRepository
+	processed map[string]bool
+}
+
+func newMemoryOutbox() *memoryOutbox {
+	return &memoryOutbox{processed: make(map[string]bool)}
+}
+
+func (o *memoryOutbox) EventProcessed(_ context.Context, consumer, eventID string) (bool, error) {
+	return o.processed[consumer+"/"+eventID], nil
+}
+
+func (o *memoryOutbox) MarkEventProcessed(_ context.Context, consumer, eventID string) error {
+	o.processed[consumer+"/"+eventID] = true
+	return nil
+}
+
+// An event is published again when one of its handlers fails. The handlers
+// that succeeded must not act on it twice.
+func TestOnceSkipsHandledEvents(t *testing.T) {
+	ctx := context.Background()
+	outbox := newMemoryOutbox()
+	bus := NewBus()
+
+	mails, notifications := 0, 0
+	bus.Subscribe(TaskCreated, Once("mail", outbox, func(context.Context, Event) error {
+		mails++
+		return nil
+	}))
+	bus.Subscribe(TaskCreated, Once("notification", outbox, func(context.Context, Event) error {
+		notifications++
+		if notifications == 1 {
+			return errors.New("failing")
+		}
+		return nil
+	}))
+
+	event := NewEvent(TaskCreated, 1, nil, nil)
+	if err := bus.Publish(ctx, event); err == nil {
+		t.Fatal("failing handler was not reported")
+	}
+	if err := bus.Publish(ctx, event); err != nil {
+		t.Fatalf("second publish: %v", err)
+	}
+	if err := bus.Publish(ctx, event); err != nil {
+		t.Fatalf("third publish: %v", err)
+	}
+
+	if mails != 1 {
+		t.Errorf("mail handler ran %d times, want once", mails)
+	}
+	if notifications != 2 {
+		t.Errorf("notification handler ran %d times, want until it succeeded", notifications)
+	}
+
+	// Another event is handled on its own.
+	if err := bus.Publish(ctx, NewEvent(TaskCreated, 1, nil, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if mails != 2 {
+		t.Errorf("mail handler ran %d times for two events", mails)
+	}
+}
+
+// Events published straight to the bus have no ID and cant be tracked.
+func TestOnceRunsEventsWithoutID(t *testing.T) {
+	calls := 0
+	handler := Once("mail", newMemoryOutbox(), func(context.Context, Event) error {
+		calls++
+		return nil
+	})
+
+	event := Event{Type: TaskCreated}
+	for i := 0; i < 2; i++ {
+		if err := handler(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for _, tt := range []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, retryBase},
+		{2, 2 * retryBase},
+		{3, 4 * retryBase},
+		{100, retryMax},
+	} {
+		if got := retry.Delay(tt.attempts); got != tt.want {
+			t.Errorf("retry.Delay(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"task-manager-backend/internal/app/config"
+	"time"
+)
+
+// Statuses of a dependency and of the service as a whole.
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDegraded = "degraded"
+	StatusDraining = "draining"
+)
+
+type Check func(context.Context) error
+
+type check struct {
+	name     string
+	critical bool
+	check    Check
+}
+
+// DependencyStatus is the result of checking one dependency.
+type DependencyStatus struct {
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is the readiness of the service. Ready is false when a critical
+// dependency is down or the service is shutting down; a failing
+// non-critical dependency only degrades it.
+type Report struct {
+	Status       string                      `json:"status"`
+	Ready        bool                        `json:"-"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+// Checker checks the dependencies of the service concurrently, each with
+// its own timeout.
+type Checker struct {
+	timeout  time.Duration
+	draining atomic.Bool
+
+	mu     sync.RWMutex
+	checks []check
+}
+
+func NewChecker(cfg config.ServiceConfiguration) *Checker {
+	return &Checker{
+		timeout: cfg.Health.CheckTimeout,
+	}
+}
+
+// Register adds a dependency. The service is not ready while a critical one
+// is down.
+func (c *Checker) Register(name string, critical bool, fn Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, check{name: name, critical: critical, check: fn})
+	sort.Slice(c.checks, func(i, j int) bool {
+		return c.checks[i].name < c.checks[j].name
+	})
+}
+
+// Drain marks the service as shutting down, so it is taken out of load
+// balancing before it stops accepting connections.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := c.checks
+	c.mu.RUnlock()
+
+	results := make([]DependencyStatus, len(checks))
+	var wg sync.WaitGroup
+	for i, dependency := range checks {
+		wg.Add(1)
+		go func(i int, dependency check) {
+			defer wg.Done()
+			results[i] = c.run(ctx, dependency)
+		}(i, dependency)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:       StatusUp,
+		Ready:        true,
+		Dependencies: make(map[string]DependencyStatus, len(checks)),
+	}
+	for i, dependency := range checks {
+		result := results[i]
+		report.Dependencies[dependency.name] = result
+		if result.Status == StatusUp {
+			continue
+		}
+		if dependency.critical {
+			report.Status = StatusDown
+			report.Ready = false
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	if c.draining.Load() {
+		report.Status = StatusDraining
+		report.Ready = false
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, dependency check) DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	started := time.Now()
+	err := dependency.check(ctx)
+	status := DependencyStatus{
+		Status:   StatusUp,
+		Critical: dependency.critical,
+		Duration: time.Since(started).Round(time.Millisecond).String(),
+	}
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,44 @@
+package health
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	retryBase = 250 * time.Millisecond
+	retryMax  = 5 * time.Second
+)
+
+// WaitFor retries the check with exponential backoff until it passes or the
+// timeout runs out. It returns the last error, after which the caller is
+// expected to carry on degraded: the clients reconnect on their own once
+// the dependency is back, and the readiness check reports it meanwhile.
+func WaitFor(logger *zap.Logger, name string, timeout time.Duration, check Check) error {
+	deadline := time.Now().Add(timeout)
+	delay := retryBase
+
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithDeadline(context.Background(), deadline)
+		err := check(ctx)
+		cancel()
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("Health: Dependency is up", zap.String("name", name), zap.Int("attempts", attempt))
+			}
+			return nil
+		}
+
+		if time.Until(deadline) < delay {
+			logger.Error("Health: Dependency is down, starting degraded", zap.String("name", name), zap.Error(err))
+			return err
+		}
+		logger.Warn("Health: Dependency is down, retrying", zap.String("name", name), zap.Duration("delay", delay), zap.Error(err))
+		time.Sleep(delay)
+
+		if delay *= 2; delay > retryMax {
+			delay = retryMax
+		}
+	}
+}
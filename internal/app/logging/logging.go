@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"context"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"task-manager-backend/internal/app/config"
+)
+
+// Formats selectable with log.format.
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// New builds the logger of the service. Its level follows log.level when
+// the configuration is reloaded. Libraries using the standard log package
+// write through it too, at info level.
+func New(lifecycle fx.Lifecycle, cfg config.ServiceConfiguration, store *config.Store) (*zap.Logger, error) {
+	level := zap.NewAtomicLevel()
+	if err := level.UnmarshalText([]byte(cfg.Log.Level)); err != nil {
+		return nil, err
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	var encoder zapcore.Encoder
+	if cfg.Log.Format == FormatConsole {
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	} else {
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	}
+
+	core := redactingCore{zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), level)}
+	logger := zap.New(core, zap.AddCaller(), zap.ErrorOutput(zapcore.Lock(os.Stderr)))
+
+	store.Subscribe(func(cfg config.ServiceConfiguration) {
+		// The level was validated on load.
+		level.UnmarshalText([]byte(cfg.Log.Level))
+	})
+
+	restore := zap.RedirectStdLog(logger)
+	lifecycle.Append(
+		fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				restore()
+				// Syncing stderr fails on some platforms; there is nothing
+				// to do about it.
+				logger.Sync()
+				return nil
+			},
+		})
+	return logger, nil
+}
+
+type contextKey struct{}
+
+// WithContext returns a context carrying the logger, which FromContext
+// finds again further down the call chain.
+func WithContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger of the request in ctx, with its request
+// ID, route and user, or fallback outside of requests.
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return fallback
+}
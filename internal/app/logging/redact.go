@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are parts of field names whose values never reach the log,
+// whatever the caller passes.
+var sensitiveKeys = []string{"password", "token", "refresh", "authorization", "secret", "cookie"}
+
+func sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveKeys {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactingCore masks the values of sensitive fields before they are
+// encoded.
+type redactingCore struct {
+	zapcore.Core
+}
+
+func (c redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactingCore{c.Core.With(redact(fields))}
+}
+
+func (c redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, redact(fields))
+}
+
+func redact(fields []zapcore.Field) []zapcore.Field {
+	var result []zapcore.Field
+	for i, field := range fields {
+		if !sensitive(field.Key) {
+			continue
+		}
+		if result == nil {
+			result = append(make([]zapcore.Field, 0, len(fields)), fields...)
+		}
+		result[i] = zap.String(field.Key, redacted)
+	}
+	if result == nil {
+		return fields
+	}
+	return result
+}
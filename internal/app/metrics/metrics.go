@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+// Namespace prefixes the names of all metrics of the service.
+const Namespace = "task_manager"
+
+// NewRegistry returns the registry served on /metrics, with the Go runtime
+// and process metrics already in it.
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// Registerer is what repositories and services take to register their
+// collectors.
+func Registerer(registry *prometheus.Registry) prometheus.Registerer {
+	return registry
+}
+
+func Handler(registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
@@ -0,0 +1,39 @@
+package invites
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/models/workspaces"
+	"time"
+)
+
+// ErrNotPending is returned when an invitation is accepted that was
+// accepted before or has expired in the meantime.
+var ErrNotPending = errors.New("invitation is no longer pending")
+
+// Invite lets the email register while registration is restricted. With a
+// workspace the new user joins it with Role straight away.
+type Invite struct {
+	Token       string          `db:"token" json:"-"`
+	Email       users.Email     `db:"email" json:"email"`
+	InviterID   users.ID        `db:"inviter_id" json:"inviter_id"`
+	WorkspaceID *workspaces.ID  `db:"workspace_id" json:"workspace_id,omitempty"`
+	Role        workspaces.Role `db:"role" json:"role,omitempty"`
+	ExpiresAt   time.Time       `db:"expires_at" json:"expires_at"`
+	AcceptedAt  *time.Time      `db:"accepted_at" json:"accepted_at,omitempty"`
+}
+
+func NewInvite(email users.Email, inviterID users.ID, ttl time.Duration) Invite {
+	return Invite{
+		Token:     uuid.New().String(),
+		Email:     email,
+		InviterID: inviterID,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+}
+
+// Pending reports whether the invitation can still be accepted.
+func (i *Invite) Pending() bool {
+	return i.AcceptedAt == nil && time.Now().Before(i.ExpiresAt)
+}
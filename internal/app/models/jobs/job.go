@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ID uint64
+type Kind string
+type Status string
+
+const (
+	Pending Status = "pending"
+	Running Status = "running"
+	Dead    Status = "dead"
+)
+
+// ErrKeyPending is returned when a keyed job is set back to pending while
+// another job with the same key is pending.
+var ErrKeyPending = errors.New("a job with the same key is already pending")
+
+// Job is a unit of background work. The payload is left out of JSON: it may
+// carry secrets such as the tokens of queued mails.
+type Job struct {
+	ID        ID              `db:"id" json:"id"`
+	Kind      Kind            `db:"kind" json:"kind"`
+	Key       *string         `db:"key" json:"key,omitempty"`
+	Payload   json.RawMessage `db:"payload" json:"-"`
+	Status    Status          `db:"status" json:"status"`
+	Attempts  int             `db:"attempts" json:"attempts"`
+	RunAt     time.Time       `db:"run_at" json:"run_at"`
+	LastError string          `db:"last_error" json:"last_error"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+func NewJob(kind Kind, payload json.RawMessage, runAt time.Time) Job {
+	now := time.Now()
+	return Job{
+		ID:        ID(uuid.New().ID()),
+		Kind:      kind,
+		Payload:   payload,
+		Status:    Pending,
+		RunAt:     runAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func ValidateStatus(status Status) bool {
+	return status == Pending || status == Running || status == Dead
+}
@@ -0,0 +1,82 @@
+package notifications
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/users"
+	"time"
+)
+
+type ID uint64
+type Channel string
+
+const (
+	InApp Channel = "in_app"
+	Email Channel = "email"
+	None  Channel = "none"
+)
+
+type Notification struct {
+	ID        ID          `db:"id" json:"id"`
+	UserID    users.ID    `db:"user_id" json:"-"`
+	EventID   string      `db:"event_id" json:"-"`
+	Type      events.Type `db:"type" json:"type"`
+	ActorID   users.ID    `db:"actor_id" json:"actor_id"`
+	Payload   Payload     `db:"payload" json:"payload"`
+	Read      bool        `db:"read" json:"read"`
+	CreatedAt time.Time   `db:"created_at" json:"created_at"`
+}
+
+type Preference struct {
+	EventType events.Type `db:"event_type" json:"event_type" binding:"required"`
+	Channel   Channel     `db:"channel" json:"channel" binding:"enum"`
+}
+
+// Payload is stored as a JSONB column.
+type Payload map[string]string
+
+func (p Payload) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(p)
+}
+
+func (p *Payload) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*p = nil
+		return nil
+	default:
+		return errors.New("notifications.Payload: unsupported type")
+	}
+	return json.Unmarshal(data, p)
+}
+
+func NewNotificationID() ID {
+	return ID(uuid.New().ID())
+}
+
+func NewNotification(userID users.ID, event events.Event) Notification {
+	return Notification{
+		ID:        NewNotificationID(),
+		UserID:    userID,
+		EventID:   event.ID,
+		Type:      event.Type,
+		ActorID:   event.ActorID,
+		Payload:   event.Payload,
+		CreatedAt: event.OccurredAt,
+	}
+}
+
+func ValidateChannel(channel Channel) bool {
+	return channel == InApp || channel == Email || channel == None
+}
@@ -0,0 +1,43 @@
+package tokens
+
+import (
+	"github.com/google/uuid"
+	"task-manager-backend/internal/app/models/users"
+	"time"
+)
+
+type Purpose string
+
+const (
+	Confirmation    Purpose = "confirmation"
+	RestorePassword Purpose = "restore_password"
+	MagicLink       Purpose = "magic_link"
+)
+
+// OneTimeToken is a single-use token bound to a purpose, so a token issued
+// for one flow can never be redeemed in another.
+type OneTimeToken struct {
+	Token     string            `json:"-"`
+	Purpose   Purpose           `json:"purpose"`
+	UserID    users.ID          `json:"user_id"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	Payload   map[string]string `json:"payload,omitempty"`
+}
+
+func NewOneTimeToken(purpose Purpose, userID users.ID, ttl time.Duration, payload map[string]string) OneTimeToken {
+	return OneTimeToken{
+		Token:     uuid.New().String(),
+		Purpose:   purpose,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(ttl),
+		Payload:   payload,
+	}
+}
+
+func (t *OneTimeToken) TTL() time.Duration {
+	return time.Until(t.ExpiresAt)
+}
+
+func (t *OneTimeToken) Expired() bool {
+	return !time.Now().Before(t.ExpiresAt)
+}
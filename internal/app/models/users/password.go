@@ -0,0 +1,136 @@
+package users
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+const (
+	argon2idPrefix = "$argon2id$"
+	bcryptPrefix   = "$2"
+	argon2SaltLen  = 16
+	argon2KeyLen   = 32
+)
+
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// Hasher produces self-describing password hashes: the algorithm and its
+// parameters are encoded in the hash itself, so DoPasswordsMatch can verify
+// any stored hash regardless of the currently configured Hasher.
+type Hasher interface {
+	Hash(password string) (string, error)
+	// NeedsRehash reports whether hashed was produced by a different
+	// algorithm or with weaker parameters than the Hasher uses now.
+	NeedsRehash(hashed string) bool
+}
+
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h BcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(hashed), err
+}
+
+func (h BcryptHasher) NeedsRehash(hashed string) bool {
+	cost, err := bcrypt.Cost([]byte(hashed))
+	return err != nil || cost < h.Cost
+}
+
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, argon2KeyLen)
+	params := argon2Params{h.Memory, h.Iterations, h.Parallelism}
+
+	return params.encode(salt, key), nil
+}
+
+func (h Argon2idHasher) NeedsRehash(hashed string) bool {
+	params, _, _, err := decodeArgon2id(hashed)
+	if err != nil {
+		return true
+	}
+	return params.memory < h.Memory || params.iterations < h.Iterations || params.parallelism < h.Parallelism
+}
+
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+func (p argon2Params) encode(salt, key []byte) string {
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version, p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+// decodeArgon2id parses the PHC string format
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>.
+func decodeArgon2id(hashed string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+
+	parts := strings.Split(hashed, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism)
+	if err != nil {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	return params, salt, key, nil
+}
+
+func DoPasswordsMatch(hashedPassword, currPassword string) bool {
+	switch {
+	case strings.HasPrefix(hashedPassword, argon2idPrefix):
+		params, salt, key, err := decodeArgon2id(hashedPassword)
+		if err != nil {
+			return false
+		}
+		actual := argon2.IDKey([]byte(currPassword), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(actual, key) == 1
+	case strings.HasPrefix(hashedPassword, bcryptPrefix):
+		err := bcrypt.CompareHashAndPassword(
+			[]byte(hashedPassword), []byte(currPassword))
+		return err == nil
+	default:
+		return false
+	}
+}
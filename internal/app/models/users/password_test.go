@@ -0,0 +1,94 @@
+package users
+
+import (
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"testing"
+)
+
+// Small parameters keep the tests fast; the format is the same.
+var testArgon2id = Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestHashRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		hasher Hasher
+		prefix string
+	}{
+		{"bcrypt", BcryptHasher{Cost: bcrypt.MinCost}, bcryptPrefix},
+		{"argon2id", testArgon2id, argon2idPrefix},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			hashed, err := tt.hasher.Hash("plain1password")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(hashed, tt.prefix) {
+				t.Fatalf("hash %q does not start with %q", hashed, tt.prefix)
+			}
+			if !DoPasswordsMatch(hashed, "plain1password") {
+				t.Fatal("hash does not match its password")
+			}
+			if DoPasswordsMatch(hashed, "plain1passwore") {
+				t.Fatal("hash matches another password")
+			}
+			if again, _ := tt.hasher.Hash("plain1password"); again == hashed {
+				t.Fatal("hashes of the same password share a salt")
+			}
+			if tt.hasher.NeedsRehash(hashed) {
+				t.Fatal("fresh hash needs a rehash")
+			}
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	bcryptHash, err := BcryptHasher{Cost: bcrypt.MinCost}.Hash("plain1password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	argon2Hash, err := testArgon2id.Hash("plain1password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		hasher Hasher
+		hashed string
+		want   bool
+	}{
+		{"bcrypt at the cost", BcryptHasher{Cost: bcrypt.MinCost}, bcryptHash, false},
+		{"bcrypt at a lower cost", BcryptHasher{Cost: bcrypt.MinCost + 1}, bcryptHash, true},
+		{"bcrypt at a higher cost", BcryptHasher{Cost: bcrypt.MinCost - 1}, bcryptHash, false},
+		{"bcrypt from argon2id", testArgon2id, bcryptHash, true},
+		{"argon2id from bcrypt", BcryptHasher{Cost: bcrypt.MinCost}, argon2Hash, true},
+		{"argon2id with more memory", Argon2idHasher{Memory: 2048, Iterations: 1, Parallelism: 1}, argon2Hash, true},
+		{"argon2id with more iterations", Argon2idHasher{Memory: 1024, Iterations: 2, Parallelism: 1}, argon2Hash, true},
+		{"argon2id with more parallelism", Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 2}, argon2Hash, true},
+		{"argon2id with weaker parameters", Argon2idHasher{Memory: 512, Iterations: 1, Parallelism: 1}, argon2Hash, false},
+		{"unknown format", testArgon2id, "plain1password", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hasher.NeedsRehash(tt.hashed); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoPasswordsMatchRejectsMalformedHashes(t *testing.T) {
+	for _, hashed := range []string{
+		"",
+		"plain1password",
+		"$argon2id$v=19$m=1024,t=1,p=1$salt",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!$a2V5",
+		"$2a$04$short",
+	} {
+		if DoPasswordsMatch(hashed, "plain1password") {
+			t.Errorf("%q matched", hashed)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package users
+
+import "time"
+
+// Profile is the public view of a user, without credentials.
+type Profile struct {
+	ID          ID                `json:"id"`
+	Email       Email             `json:"email"`
+	DisplayName string            `json:"display_name"`
+	Avatars     map[string]string `json:"avatars,omitempty"`
+	Timezone    string            `json:"timezone"`
+	Locale      string            `json:"locale"`
+	Bio         string            `json:"bio"`
+	RegDate     time.Time         `json:"reg_date"`
+	LastLogin   *time.Time        `json:"last_login,omitempty"`
+}
+
+// ProfileUpdate holds the fields of a partial profile update; nil fields are
+// left unchanged.
+type ProfileUpdate struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,max=64"`
+	Timezone    *string `json:"timezone" binding:"omitempty,timezone"`
+	Locale      *string `json:"locale" binding:"omitempty,locale"`
+	Bio         *string `json:"bio" binding:"omitempty,max=500"`
+}
+
+func (usr *User) Profile() Profile {
+	return Profile{
+		ID:          usr.ID,
+		Email:       usr.Email,
+		DisplayName: usr.DisplayName,
+		Timezone:    usr.Timezone,
+		Locale:      usr.Locale,
+		Bio:         usr.Bio,
+		RegDate:     usr.RegDate,
+		LastLogin:   usr.LastLogin,
+	}
+}
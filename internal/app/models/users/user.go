@@ -2,22 +2,44 @@ package users
 
 import (
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 	"regexp"
+	"time"
 )
 
 var patterns = map[string]string{
 	"email": `\w+([-+.']\w+)*@\w+([-.]\w+)*\.\w+([-.]\w+)*$`,
 }
 
+const (
+	StatusSimple = "simple"
+	StatusAdmin  = "admin"
+)
+
+const (
+	DefaultTimezone = "UTC"
+	DefaultLocale   = "ru"
+)
+
 type ID uint64
 type Email string
 type User struct {
-	ID        ID     `db:"id" json:"id"`
-	Email     Email  `db:"email" json:"email"`
-	PwdHash   string `db:"pwd_hash" json:"pwd_hash"`
-	Status    string `db:"status" json:"status"`
-	Confirmed bool   `db:"confirmed" json:"confirmed"`
+	ID        ID        `db:"id" json:"id"`
+	Email     Email     `db:"email" json:"email"`
+	PwdHash   string    `db:"pwd_hash" json:"pwd_hash"`
+	Status    string    `db:"status" json:"status"`
+	Confirmed bool      `db:"confirmed" json:"confirmed"`
+	RegDate   time.Time `db:"reg_date" json:"reg_date"`
+
+	DisplayName string     `db:"display_name" json:"display_name"`
+	Avatar      string     `db:"avatar" json:"avatar"`
+	Timezone    string     `db:"timezone" json:"timezone"`
+	Locale      string     `db:"locale" json:"locale"`
+	Bio         string     `db:"bio" json:"bio"`
+	LastLogin   *time.Time `db:"last_login" json:"last_login"`
+
+	// LegacyPassword marks a PwdHash made from the client-side pwd_hash of
+	// the v1 API rather than from the password itself.
+	LegacyPassword bool `db:"legacy_password" json:"-"`
 }
 
 func NewUserID() ID {
@@ -31,6 +53,9 @@ func NewUser(email Email, pwdhash string, status string) User {
 		PwdHash:   pwdhash,
 		Status:    status,
 		Confirmed: false,
+		RegDate:   time.Now(),
+		Timezone:  DefaultTimezone,
+		Locale:    DefaultLocale,
 	}
 }
 
@@ -51,9 +76,3 @@ func (usr *User) CheckCerds(email Email, pwdhash string) bool {
 
 	return DoPasswordsMatch(string(usr.PwdHash), string(pwdhash))
 }
-
-func DoPasswordsMatch(hashedPassword, currPassword string) bool {
-	err := bcrypt.CompareHashAndPassword(
-		[]byte(hashedPassword), []byte(currPassword))
-	return err == nil
-}
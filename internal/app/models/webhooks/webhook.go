@@ -0,0 +1,91 @@
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/workspaces"
+	"time"
+)
+
+type ID uint64
+type DeliveryID uint64
+type Status string
+
+const (
+	Pending   Status = "pending"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+)
+
+const secretBytes = 32
+
+type Webhook struct {
+	ID          ID             `db:"id" json:"id"`
+	WorkspaceID workspaces.ID  `db:"workspace_id" json:"workspace_id"`
+	URL         string         `db:"url" json:"url"`
+	Secret      string         `db:"secret" json:"secret,omitempty"`
+	EventTypes  pq.StringArray `db:"event_types" json:"event_types" swaggertype:"array,string"`
+	Active      bool           `db:"active" json:"active"`
+	Failures    int            `db:"failures" json:"failures"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+}
+
+type Delivery struct {
+	ID             DeliveryID      `db:"id" json:"id"`
+	WebhookID      ID              `db:"webhook_id" json:"webhook_id"`
+	WorkspaceID    workspaces.ID   `db:"workspace_id" json:"-"`
+	EventType      events.Type     `db:"event_type" json:"event_type"`
+	Payload        json.RawMessage `db:"payload" json:"payload" swaggertype:"object"`
+	Status         Status          `db:"status" json:"status"`
+	Attempts       int             `db:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time       `db:"next_attempt_at" json:"next_attempt_at"`
+	ResponseStatus int             `db:"response_status" json:"response_status"`
+	LastError      string          `db:"last_error" json:"last_error"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+func NewWebhook(workspaceID workspaces.ID, url string, eventTypes []string) (Webhook, error) {
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return Webhook{}, err
+	}
+
+	return Webhook{
+		ID:          ID(uuid.New().ID()),
+		WorkspaceID: workspaceID,
+		URL:         url,
+		Secret:      hex.EncodeToString(secret),
+		EventTypes:  eventTypes,
+		Active:      true,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+func NewDelivery(webhook Webhook, eventType events.Type, payload json.RawMessage) Delivery {
+	now := time.Now()
+	return Delivery{
+		ID:            DeliveryID(uuid.New().ID()),
+		WebhookID:     webhook.ID,
+		WorkspaceID:   webhook.WorkspaceID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        Pending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+func (w *Webhook) Subscribed(eventType events.Type) bool {
+	for _, subscribed := range w.EventTypes {
+		if events.Type(subscribed) == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package workspaces
+
+import (
+	"github.com/google/uuid"
+	"task-manager-backend/internal/app/models/users"
+	"time"
+)
+
+type ID uint64
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+)
+
+type Workspace struct {
+	ID        ID        `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	OwnerID   users.ID  `db:"owner_id" json:"owner_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type Member struct {
+	WorkspaceID ID       `db:"workspace_id" json:"workspace_id"`
+	UserID      users.ID `db:"user_id" json:"user_id"`
+	Role        Role     `db:"role" json:"role"`
+}
+
+func NewWorkspaceID() ID {
+	return ID(uuid.New().ID())
+}
+
+func NewWorkspace(name string, ownerID users.ID) Workspace {
+	return Workspace{
+		ID:        NewWorkspaceID(),
+		Name:      name,
+		OwnerID:   ownerID,
+		CreatedAt: time.Now(),
+	}
+}
+
+func ValidateRole(role Role) bool {
+	return role == RoleOwner || role == RoleAdmin || role == RoleMember
+}
+
+func (r Role) CanManageMembers() bool {
+	return r == RoleOwner || r == RoleAdmin
+}
@@ -0,0 +1,250 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"sync"
+	"task-manager-backend/internal/app/errs"
+	"task-manager-backend/internal/app/models/jobs"
+	"task-manager-backend/internal/app/worker"
+	"time"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+
+	// MaxAttempts is how many times a job runs before it is dead-lettered.
+	MaxAttempts = 5
+
+	pollInterval   = time.Second
+	claimBatch     = 20
+	claimLease     = 5 * time.Minute
+	backoffBase    = 10 * time.Second
+	backoffMax     = time.Hour
+	maxErrorLength = 500
+)
+
+var (
+	InvalidData = errs.New(errs.Invalid, errs.CodeInvalid, "Invalid data")
+	NotFound    = errs.New(errs.NotFound, "job_not_found", "Job not found")
+	NotDead     = errs.New(errs.Conflict, "job_not_dead", "Only dead jobs can be retried")
+	KeyPending  = errs.New(errs.Conflict, "job_key_pending", "A job with the same key is already pending")
+
+	retry = worker.Backoff{Base: backoffBase, Max: backoffMax}
+)
+
+type Repository interface {
+	CreateJob(context.Context, jobs.Job) (bool, error)
+	ClaimJobs(context.Context, uint64, time.Duration) ([]jobs.Job, error)
+	UpdateJob(context.Context, jobs.Job) error
+	DeleteJob(context.Context, jobs.ID) error
+	GetJobs(context.Context, jobs.Status, uint64, uint64) ([]jobs.Job, error)
+	GetJob(context.Context, jobs.ID) (jobs.Job, error)
+}
+
+// Handler runs a job with its payload. A returned error fails the attempt;
+// wrap it with Permanent when retrying cannot help.
+type Handler func(context.Context, json.RawMessage) error
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as one that dead-letters the job right away.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+func NewQueue(repository Repository, logger *zap.Logger) *Queue {
+	return &Queue{
+		repository: repository,
+		logger:     logger,
+		handlers:   make(map[jobs.Kind]Handler),
+	}
+}
+
+// Queue is a durable job queue kept in Postgres. Jobs survive restarts, are
+// retried with exponential backoff and end up dead after MaxAttempts, where
+// they stay for inspection until retried by hand.
+type Queue struct {
+	repository Repository
+	logger     *zap.Logger
+
+	mu       sync.RWMutex
+	handlers map[jobs.Kind]Handler
+}
+
+func (q *Queue) Register(kind jobs.Kind, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.handlers[kind] = handler
+}
+
+// Enqueue queues a job to run as soon as a worker is free.
+func (q *Queue) Enqueue(ctx context.Context, kind jobs.Kind, payload interface{}) error {
+	_, err := q.create(ctx, kind, nil, payload, time.Now())
+	return err
+}
+
+// Schedule queues a job to run at runAt unless a job with the same key is
+// already waiting, which makes it safe for every instance to schedule
+// recurring work.
+func (q *Queue) Schedule(ctx context.Context, kind jobs.Kind, key string, payload interface{}, runAt time.Time) (bool, error) {
+	return q.create(ctx, kind, &key, payload, runAt)
+}
+
+func (q *Queue) create(ctx context.Context, kind jobs.Kind, key *string, payload interface{}, runAt time.Time) (bool, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return false, err
+	}
+
+	job := jobs.NewJob(kind, data, runAt)
+	job.Key = key
+	return q.repository.CreateJob(ctx, job)
+}
+
+func (q *Queue) List(ctx context.Context, status jobs.Status, limit, offset uint64) ([]jobs.Job, error) {
+	if !jobs.ValidateStatus(status) {
+		return nil, InvalidData
+	}
+
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	return q.repository.GetJobs(ctx, status, limit, offset)
+}
+
+// Retry gives a dead job a fresh set of attempts. A keyed job cant be
+// retried while another job with its key is pending.
+func (q *Queue) Retry(ctx context.Context, jobID jobs.ID) (jobs.Job, error) {
+	job, err := q.repository.GetJob(ctx, jobID)
+	if err != nil {
+		return jobs.Job{}, err
+	}
+	if job.ID != jobID {
+		return jobs.Job{}, NotFound
+	}
+	if job.Status != jobs.Dead {
+		return jobs.Job{}, NotDead
+	}
+
+	job.Status = jobs.Pending
+	job.Attempts = 0
+	job.RunAt = time.Now()
+	job.UpdatedAt = job.RunAt
+	err = q.repository.UpdateJob(ctx, job)
+	if errors.Is(err, jobs.ErrKeyPending) {
+		return jobs.Job{}, KeyPending
+	}
+	return job, err
+}
+
+// Hook runs the worker for the lifetime of the app. Stopping waits for the
+// jobs being run to finish.
+func Hook(lifecycle fx.Lifecycle, queue *Queue) {
+	worker.Hook(lifecycle, queue.work)
+}
+
+func (q *Queue) work(stop <-chan struct{}) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			q.runDue(context.Background())
+		}
+	}
+}
+
+func (q *Queue) runDue(ctx context.Context) {
+	for {
+		claimed, err := q.repository.ClaimJobs(ctx, claimBatch, claimLease)
+		if err != nil {
+			q.logger.Error("Jobs: Cant claim jobs", zap.Error(err))
+			return
+		}
+
+		for _, job := range claimed {
+			if err := q.run(ctx, job); err != nil {
+				q.logger.Error("Jobs: Cant record job", zap.Uint64("job_id", uint64(job.ID)), zap.Error(err))
+			}
+		}
+
+		if len(claimed) < claimBatch {
+			return
+		}
+	}
+}
+
+// run executes the job once. Finished jobs are deleted; failed ones are
+// rescheduled or dead-lettered. A keyed job scheduled again while it ran is
+// left to the new one instead of being rescheduled.
+func (q *Queue) run(ctx context.Context, job jobs.Job) error {
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Kind]
+	q.mu.RUnlock()
+
+	var err error
+	if ok {
+		err = call(ctx, handler, job.Payload)
+	} else {
+		err = Permanent(fmt.Errorf("no handler for job kind %q", job.Kind))
+	}
+
+	if err == nil {
+		return q.repository.DeleteJob(ctx, job.ID)
+	}
+
+	job.UpdatedAt = time.Now()
+	job.LastError = worker.Truncate(err.Error(), maxErrorLength)
+
+	var permanent permanentError
+	if errors.As(err, &permanent) || job.Attempts >= MaxAttempts {
+		job.Status = jobs.Dead
+		q.logger.Warn("Jobs: Job is dead",
+			zap.String("kind", string(job.Kind)),
+			zap.Uint64("job_id", uint64(job.ID)),
+			zap.Int("attempts", job.Attempts),
+			zap.Error(err))
+	} else {
+		job.Status = jobs.Pending
+		job.RunAt = job.UpdatedAt.Add(retry.Delay(job.Attempts))
+	}
+	err = q.repository.UpdateJob(ctx, job)
+	if errors.Is(err, jobs.ErrKeyPending) {
+		return q.repository.DeleteJob(ctx, job.ID)
+	}
+	return err
+}
+
+// call runs the handler, turning a panic into a failed attempt so one bad
+// job cannot take the worker down.
+func call(ctx context.Context, handler Handler, payload json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, payload)
+}
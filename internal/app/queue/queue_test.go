@@ -0,0 +1,186 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"strings"
+	"task-manager-backend/internal/app/models/jobs"
+	"testing"
+	"time"
+)
+
+// memoryRepository keeps jobs in a map and enforces the unique key of
+// pending jobs like the jobs_pending_key_idx index does.
+type memoryRepository struct {
+	jobs map[jobs.ID]jobs.Job
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{jobs: make(map[jobs.ID]jobs.Job)}
+}
+
+func (r *memoryRepository) keyPending(job jobs.Job) bool {
+	if job.Key == nil || job.Status != jobs.Pending {
+		return false
+	}
+	for _, other := range r.jobs {
+		if other.ID != job.ID && other.Key != nil && *other.Key == *job.Key && other.Status == jobs.Pending {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryRepository) CreateJob(_ context.Context, job jobs.Job) (bool, error) {
+	if r.keyPending(job) {
+		return false, nil
+	}
+	r.jobs[job.ID] = job
+	return true, nil
+}
+
+func (r *memoryRepository) ClaimJobs(context.Context, uint64, time.Duration) ([]jobs.Job, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *memoryRepository) UpdateJob(_ context.Context, job jobs.Job) error {
+	if r.keyPending(job) {
+		return jobs.ErrKeyPending
+	}
+	r.jobs[job.ID] = job
+	return nil
+}
+
+func (r *memoryRepository) DeleteJob(_ context.Context, jobID jobs.ID) error {
+	delete(r.jobs, jobID)
+	return nil
+}
+
+func (r *memoryRepository) GetJobs(context.Context, jobs.Status, uint64, uint64) ([]jobs.Job, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *memoryRepository) GetJob(_ context.Context, jobID jobs.ID) (jobs.Job, error) {
+	return r.jobs[jobID], nil
+}
+
+const testKind = jobs.Kind("test")
+
+// running returns a keyed job as ClaimJobs would.
+func running(key string, attempts int) jobs.Job {
+	job := jobs.NewJob(testKind, json.RawMessage(`{}`), time.Now())
+	job.Key = &key
+	job.Status = jobs.Running
+	job.Attempts = attempts
+	return job
+}
+
+func TestRun(t *testing.T) {
+	failing := errors.New("failing")
+	for _, tt := range []struct {
+		name     string
+		err      error
+		attempts int
+		want     jobs.Status
+	}{
+		{"success", nil, 1, ""},
+		{"failure", failing, 1, jobs.Pending},
+		{"last attempt", failing, MaxAttempts, jobs.Dead},
+		{"permanent failure", Permanent(failing), 1, jobs.Dead},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := newMemoryRepository()
+			q := NewQueue(repository, zap.NewNop())
+			q.Register(testKind, func(context.Context, json.RawMessage) error {
+				return tt.err
+			})
+			job := running("key", tt.attempts)
+			repository.jobs[job.ID] = job
+
+			if err := q.run(context.Background(), job); err != nil {
+				t.Fatal(err)
+			}
+			if got := repository.jobs[job.ID].Status; got != tt.want {
+				t.Fatalf("job left %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunLeavesRescheduledKeyToNewJob(t *testing.T) {
+	ctx := context.Background()
+	repository := newMemoryRepository()
+	q := NewQueue(repository, zap.NewNop())
+	q.Register(testKind, func(ctx context.Context, _ json.RawMessage) error {
+		// Another instance schedules the job again while it runs.
+		if _, err := q.Schedule(ctx, testKind, "key", nil, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		return errors.New("failing")
+	})
+	job := running("key", 1)
+	repository.jobs[job.ID] = job
+
+	if err := q.run(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := repository.jobs[job.ID]; ok {
+		t.Fatal("failed job was kept besides the one scheduled with its key")
+	}
+	if len(repository.jobs) != 1 {
+		t.Fatalf("%d jobs queued, want the newly scheduled one", len(repository.jobs))
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	repository := newMemoryRepository()
+	q := NewQueue(repository, zap.NewNop())
+
+	dead := running("key", MaxAttempts)
+	dead.Status = jobs.Dead
+	repository.jobs[dead.ID] = dead
+	if _, err := q.Schedule(ctx, testKind, "key", nil, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := q.Retry(ctx, dead.ID); err != KeyPending {
+		t.Fatalf("retry beside a pending job with the key: got %v, want %v", err, KeyPending)
+	}
+	if got := repository.jobs[dead.ID].Status; got != jobs.Dead {
+		t.Fatalf("job left %q, want %q", got, jobs.Dead)
+	}
+
+	for id, job := range repository.jobs {
+		if job.Status == jobs.Pending {
+			delete(repository.jobs, id)
+		}
+	}
+	retried, err := q.Retry(ctx, dead.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retried.Status != jobs.Pending || retried.Attempts != 0 {
+		t.Fatalf("retried job is %q after %d attempts, want pending with none", retried.Status, retried.Attempts)
+	}
+
+	if _, err := q.Retry(ctx, dead.ID); err != NotDead {
+		t.Fatalf("retry of a pending job: got %v, want %v", err, NotDead)
+	}
+	if _, err := q.Retry(ctx, dead.ID+1); err != NotFound {
+		t.Fatalf("retry of a missing job: got %v, want %v", err, NotFound)
+	}
+}
+
+func TestJobPayloadIsNotMarshalled(t *testing.T) {
+	job := jobs.NewJob(testKind, json.RawMessage(`{"token":"secret"}`), time.Now())
+	data, err := json.Marshal(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Fatalf("payload is exposed: %s", data)
+	}
+}
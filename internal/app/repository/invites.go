@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/invites"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/models/workspaces"
+)
+
+const (
+	invitationsTable = "invitations"
+	Token            = "token"
+	InviterID        = "inviter_id"
+	ExpiresAt        = "expires_at"
+	AcceptedAt       = "accepted_at"
+)
+
+var inviteColumns = []string{
+	Token, Email, InviterID, WorkspaceID, Role, ExpiresAt, AcceptedAt,
+}
+
+func (p *PostgresRepository) CreateInvite(ctx context.Context, invite invites.Invite) error {
+	query, args, err := sq.
+		Insert(invitationsTable).
+		Columns(Token, Email, InviterID, WorkspaceID, Role, ExpiresAt).
+		Values(
+			invite.Token,
+			invite.Email,
+			invite.InviterID,
+			invite.WorkspaceID,
+			invite.Role,
+			invite.ExpiresAt,
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (p *PostgresRepository) GetInvite(ctx context.Context, token string) (invites.Invite, error) {
+	var invite invites.Invite
+
+	query, args, err := sq.
+		Select(inviteColumns...).
+		From(invitationsTable).
+		Where(
+			sq.Eq{
+				Token: token,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return invite, err
+	}
+
+	err = p.db.GetContext(ctx, &invite, query, args...)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return invite, err
+}
+
+// CreateInvitedUser accepts the invitation and stores the user, the
+// workspace membership the invitation grants and the events announcing
+// them in one transaction. invites.ErrNotPending means the invitation was
+// accepted concurrently or has expired, and nothing is stored.
+func (p *PostgresRepository) CreateInvitedUser(ctx context.Context, user users.User, invite invites.Invite, announce ...events.Event) error {
+	accept, acceptArgs, err := sq.
+		Update(invitationsTable).
+		Set(AcceptedAt, sq.Expr("NOW()")).
+		Where(
+			sq.And{
+				sq.Eq{Token: invite.Token, AcceptedAt: nil},
+				sq.Expr(ExpiresAt + " > NOW()"),
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	return p.inTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, accept, acceptArgs...)
+		if err != nil {
+			return err
+		}
+		if accepted, err := result.RowsAffected(); err != nil {
+			return err
+		} else if accepted == 0 {
+			return invites.ErrNotPending
+		}
+
+		if err = insertUser(ctx, tx, user); err != nil {
+			return err
+		}
+
+		if invite.WorkspaceID != nil {
+			if err = setSetting(ctx, tx, workspaceSetting, workspaceSettingValue(*invite.WorkspaceID)); err != nil {
+				return err
+			}
+			member := workspaces.Member{
+				WorkspaceID: *invite.WorkspaceID,
+				UserID:      user.ID,
+				Role:        invite.Role,
+			}
+			if err = addMember(ctx, tx, member); err != nil {
+				return err
+			}
+		}
+
+		return appendOutbox(ctx, tx, announce)
+	})
+}
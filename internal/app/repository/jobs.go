@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+	"strings"
+	"task-manager-backend/internal/app/models/jobs"
+	"time"
+)
+
+const (
+	jobsTable         = "jobs"
+	jobsPendingKeyIdx = "jobs_pending_key_idx"
+	Kind              = "kind"
+	Key               = "key"
+	RunAt             = "run_at"
+)
+
+var jobColumns = []string{ID, Kind, Key, Payload, Status, Attempts, RunAt, LastError, CreatedAt, UpdatedAt}
+
+// CreateJob queues the job. A keyed job is skipped while another pending job
+// has the same key; the result reports whether the job was queued.
+func (p *PostgresRepository) CreateJob(ctx context.Context, job jobs.Job) (bool, error) {
+	query, args, err := sq.
+		Insert(jobsTable).
+		Columns(jobColumns...).
+		Values(
+			job.ID,
+			job.Kind,
+			job.Key,
+			string(job.Payload),
+			job.Status,
+			job.Attempts,
+			job.RunAt,
+			job.LastError,
+			job.CreatedAt,
+			job.UpdatedAt,
+		).
+		Suffix("ON CONFLICT (" + Key + ") WHERE " + Status + " = '" + string(jobs.Pending) + "' DO NOTHING").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return false, err
+	}
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ClaimJobs marks up to limit due jobs as running and counts the attempt.
+// A running job whose lease ran out belongs to a worker that died and is
+// claimed again.
+func (p *PostgresRepository) ClaimJobs(ctx context.Context, limit uint64, lease time.Duration) ([]jobs.Job, error) {
+	result := make([]jobs.Job, 0)
+	now := time.Now()
+
+	due, dueArgs, err := sq.
+		Select(ID).
+		From(jobsTable).
+		Where(
+			sq.And{
+				sq.Eq{
+					Status: []jobs.Status{jobs.Pending, jobs.Running},
+				},
+				sq.LtOrEq{
+					RunAt: now,
+				},
+			},
+		).
+		OrderBy(RunAt).
+		Limit(limit).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return result, err
+	}
+
+	query, args, err := sq.
+		Update(jobsTable).
+		Set(Status, jobs.Running).
+		Set(Attempts, sq.Expr(Attempts+" + 1")).
+		Set(RunAt, now.Add(lease)).
+		Set(UpdatedAt, now).
+		Where(sq.Expr(ID+" IN ("+due+")", dueArgs...)).
+		Suffix("RETURNING " + strings.Join(jobColumns, ", ")).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return result, err
+	}
+
+	err = p.db.SelectContext(ctx, &result, query, args...)
+	return result, err
+}
+
+// UpdateJob stores the outcome of a failed attempt. jobs.ErrKeyPending means
+// the job was to be pending again but another job with its key already is.
+func (p *PostgresRepository) UpdateJob(ctx context.Context, job jobs.Job) error {
+	query, args, err := sq.
+		Update(jobsTable).
+		SetMap(
+			map[string]interface{}{
+				Status:    job.Status,
+				Attempts:  job.Attempts,
+				RunAt:     job.RunAt,
+				LastError: job.LastError,
+				UpdatedAt: job.UpdatedAt,
+			},
+		).
+		Where(
+			sq.Eq{
+				ID: job.ID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, query, args...)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == jobsPendingKeyIdx {
+		return jobs.ErrKeyPending
+	}
+	return err
+}
+
+func (p *PostgresRepository) DeleteJob(ctx context.Context, jobID jobs.ID) error {
+	query, args, err := sq.
+		Delete(jobsTable).
+		Where(
+			sq.Eq{
+				ID: jobID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (p *PostgresRepository) GetJobs(ctx context.Context, status jobs.Status, limit, offset uint64) ([]jobs.Job, error) {
+	result := make([]jobs.Job, 0)
+
+	query, args, err := sq.
+		Select(jobColumns...).
+		From(jobsTable).
+		Where(
+			sq.Eq{
+				Status: status,
+			},
+		).
+		OrderBy(UpdatedAt + " DESC").
+		Limit(limit).
+		Offset(offset).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return result, err
+	}
+
+	err = p.db.SelectContext(ctx, &result, query, args...)
+	return result, err
+}
+
+func (p *PostgresRepository) GetJob(ctx context.Context, jobID jobs.ID) (jobs.Job, error) {
+	var job jobs.Job
+
+	query, args, err := sq.
+		Select(jobColumns...).
+		From(jobsTable).
+		Where(
+			sq.Eq{
+				ID: jobID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return job, err
+	}
+
+	err = p.db.GetContext(ctx, &job, query, args...)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return job, err
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"task-manager-backend/internal/app/models/jobs"
+	"testing"
+	"time"
+)
+
+// claimedJobs claims what is due and keeps the jobs among ids. The queue is
+// shared with whatever else is in the database.
+func claimedJobs(t *testing.T, ctx context.Context, p *PostgresRepository, lease time.Duration, ids ...jobs.ID) map[jobs.ID]jobs.Job {
+	t.Helper()
+	claimed, err := p.ClaimJobs(ctx, 1000, lease)
+	if err != nil {
+		t.Fatalf("ClaimJobs: %v", err)
+	}
+	result := make(map[jobs.ID]jobs.Job)
+	for _, job := range claimed {
+		for _, id := range ids {
+			if job.ID == id {
+				result[id] = job
+			}
+		}
+	}
+	return result
+}
+
+func newTestJob(t *testing.T, p *PostgresRepository, key string, runAt time.Time) jobs.Job {
+	t.Helper()
+	job := jobs.NewJob("test", json.RawMessage(`{}`), runAt)
+	if key != "" {
+		job.Key = &key
+	}
+	created, err := p.CreateJob(context.Background(), job)
+	if err != nil || !created {
+		t.Fatalf("CreateJob: created %v, error %v", created, err)
+	}
+	return job
+}
+
+func TestClaimJobs(t *testing.T) {
+	p := newTestRepository(t)
+	ctx := context.Background()
+
+	due := newTestJob(t, p, "", time.Now())
+	later := newTestJob(t, p, "", time.Now().Add(time.Hour))
+	locked := newTestJob(t, p, "", time.Now())
+
+	// Another worker in the middle of claiming holds the row lock.
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "SELECT id FROM jobs WHERE id = $1 FOR UPDATE", locked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	claimCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	claimed := claimedJobs(t, claimCtx, p, time.Minute, due.ID, later.ID, locked.ID)
+	if len(claimed) != 1 || claimed[due.ID].Status != jobs.Running || claimed[due.ID].Attempts != 1 {
+		t.Fatalf("claimed %+v, want only the due job running its first attempt", claimed)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	claimed = claimedJobs(t, ctx, p, -time.Second, due.ID, later.ID, locked.ID)
+	if len(claimed) != 1 || claimed[locked.ID].Attempts != 1 {
+		t.Fatalf("claimed %+v, want the unlocked job and not the leased one", claimed)
+	}
+
+	// The lease above already ran out, as if the worker died mid-run.
+	claimed = claimedJobs(t, ctx, p, time.Minute, locked.ID)
+	if claimed[locked.ID].Attempts != 2 {
+		t.Fatalf("claimed %+v, want the job again once its lease ran out", claimed)
+	}
+}
+
+func TestUpdateJobKeepsKeyUnique(t *testing.T) {
+	p := newTestRepository(t)
+	ctx := context.Background()
+	key := uuid.NewString()
+
+	dead := newTestJob(t, p, key, time.Now().Add(time.Hour))
+	dead.Status = jobs.Dead
+	if err := p.UpdateJob(ctx, dead); err != nil {
+		t.Fatalf("UpdateJob: %v", err)
+	}
+	newTestJob(t, p, key, time.Now().Add(time.Hour))
+
+	dead.Status = jobs.Pending
+	if err := p.UpdateJob(ctx, dead); err != jobs.ErrKeyPending {
+		t.Fatalf("got %v, want %v", err, jobs.ErrKeyPending)
+	}
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	sq "github.com/Masterminds/squirrel"
+	"task-manager-backend/internal/app/models/notifications"
+	"task-manager-backend/internal/app/models/users"
+)
+
+const (
+	notificationsTable           = "notifications"
+	notificationPreferencesTable = "notification_preferences"
+	Type                         = "type"
+	ActorID                      = "actor_id"
+	Payload                      = "payload"
+	Read                         = "read"
+	EventType                    = "event_type"
+	Channel                      = "channel"
+)
+
+// CreateNotification stores the notification unless the user already has one
+// for the same event, which happens when the event is handled again.
+func (p *PostgresRepository) CreateNotification(ctx context.Context, notification notifications.Notification) error {
+	query, args, err := sq.
+		Insert(notificationsTable).
+		Columns(ID, UserID, EventID, Type, ActorID, Payload, CreatedAt).
+		Values(
+			notification.ID,
+			notification.UserID,
+			sq.Expr("NULLIF(?, '')", notification.EventID),
+			notification.Type,
+			notification.ActorID,
+			notification.Payload,
+			notification.CreatedAt,
+		).
+		Suffix("ON CONFLICT (" + UserID + ", " + EventID + ") DO NOTHING").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (p *PostgresRepository) GetNotifications(ctx context.Context, userID users.ID, unreadOnly bool, limit, offset uint64) ([]notifications.Notification, error) {
+	result := make([]notifications.Notification, 0)
+
+	where := sq.Eq{UserID: userID}
+	if unreadOnly {
+		where[Read] = false
+	}
+
+	query, args, err := sq.
+		Select(ID, UserID, Type, ActorID, Payload, Read, CreatedAt).
+		From(notificationsTable).
+		Where(where).
+		OrderBy(CreatedAt + " DESC").
+		Limit(limit).
+		Offset(offset).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return result, err
+	}
+
+	err = p.db.SelectContext(ctx, &result, query, args...)
+	return result, err
+}
+
+func (p *PostgresRepository) CountUnreadNotifications(ctx context.Context, userID users.ID) (int64, error) {
+	var count int64
+
+	query, args, err := sq.
+		Select("COUNT(*)").
+		From(notificationsTable).
+		Where(
+			sq.Eq{
+				UserID: userID,
+				Read:   false,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return count, err
+	}
+
+	err = p.db.GetContext(ctx, &count, query, args...)
+	return count, err
+}
+
+func (p *PostgresRepository) MarkNotificationRead(ctx context.Context, userID users.ID, notificationID notifications.ID) (bool, error) {
+	query, args, err := sq.
+		Update(notificationsTable).
+		Set(Read, true).
+		Where(
+			sq.Eq{
+				ID:     notificationID,
+				UserID: userID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return false, err
+	}
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (p *PostgresRepository) MarkAllNotificationsRead(ctx context.Context, userID users.ID) error {
+	query, args, err := sq.
+		Update(notificationsTable).
+		Set(Read, true).
+		Where(
+			sq.Eq{
+				UserID: userID,
+				Read:   false,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (p *PostgresRepository) GetNotificationPreferences(ctx context.Context, userID users.ID) ([]notifications.Preference, error) {
+	result := make([]notifications.Preference, 0)
+
+	query, args, err := sq.
+		Select(EventType, Channel).
+		From(notificationPreferencesTable).
+		Where(
+			sq.Eq{
+				UserID: userID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return result, err
+	}
+
+	err = p.db.SelectContext(ctx, &result, query, args...)
+	return result, err
+}
+
+func (p *PostgresRepository) SetNotificationPreference(ctx context.Context, userID users.ID, preference notifications.Preference) error {
+	query, args, err := sq.
+		Insert(notificationPreferencesTable).
+		Columns(UserID, EventType, Channel).
+		Values(
+			userID,
+			preference.EventType,
+			preference.Channel,
+		).
+		Suffix("ON CONFLICT (" + UserID + ", " + EventType + ") DO UPDATE SET " + Channel + " = EXCLUDED." + Channel).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, query, args...)
+	return err
+}
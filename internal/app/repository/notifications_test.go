@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/notifications"
+	"task-manager-backend/internal/app/models/users"
+	"testing"
+)
+
+func TestCreateNotificationOncePerEvent(t *testing.T) {
+	p := newTestRepository(t)
+	ctx := context.Background()
+	user := newTestUser()
+	if err := p.CreateUser(ctx, user); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	event := events.NewEvent(events.TaskAssigned, 0, []users.ID{user.ID}, nil)
+	for i := 0; i < 2; i++ {
+		if err := p.CreateNotification(ctx, notifications.NewNotification(user.ID, event)); err != nil {
+			t.Fatalf("CreateNotification: %v", err)
+		}
+	}
+	// Events published straight to the bus have no ID and are not deduplicated.
+	event.ID = ""
+	for i := 0; i < 2; i++ {
+		if err := p.CreateNotification(ctx, notifications.NewNotification(user.ID, event)); err != nil {
+			t.Fatalf("CreateNotification without event: %v", err)
+		}
+	}
+
+	list, err := p.GetNotifications(ctx, user.ID, false, 10, 0)
+	if err != nil {
+		t.Fatalf("GetNotifications: %v", err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("got %d notifications, want 3", len(list))
+	}
+}
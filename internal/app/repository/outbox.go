@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"task-manager-backend/internal/app/events"
+	"time"
+)
+
+const (
+	outboxTable          = "outbox"
+	processedEventsTable = "processed_events"
+	EventID              = "event_id"
+	Event                = "event"
+	AvailableAt          = "available_at"
+	Consumer             = "consumer"
+	ProcessedAt          = "processed_at"
+)
+
+type outboxRecord struct {
+	ID       int64           `db:"id"`
+	Event    json.RawMessage `db:"event"`
+	Attempts int             `db:"attempts"`
+}
+
+// appendOutbox records the events in the transaction of the change they
+// describe. They are published by the relay once the transaction commits,
+// so a crash can no longer lose an event or publish one for a rolled back
+// change.
+func appendOutbox(ctx context.Context, tx *sqlx.Tx, list []events.Event) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	insert := sq.
+		Insert(outboxTable).
+		Columns(EventID, Event)
+	for _, event := range list {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		insert = insert.Values(event.ID, string(data))
+	}
+
+	query, args, err := insert.
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// RelayOutbox publishes up to limit available events in the order they were
+// recorded. Published events are removed; an event whose publishing failed
+// is put back with its retry delay. Rows are locked with SKIP LOCKED, so
+// relays of several instances share the work. An event may be published
+// again if the process dies before the removal commits.
+func (p *PostgresRepository) RelayOutbox(ctx context.Context, limit uint64, publish func(context.Context, events.Event) error, retryDelay func(attempts int) time.Duration) (int, error) {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query, args, err := sq.
+		Select(ID, Event, Attempts).
+		From(outboxTable).
+		Where(
+			sq.LtOrEq{
+				AvailableAt: time.Now(),
+			},
+		).
+		OrderBy(ID).
+		Limit(limit).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	records := make([]outboxRecord, 0)
+	if err = tx.SelectContext(ctx, &records, query, args...); err != nil {
+		return 0, err
+	}
+
+	published := make([]int64, 0, len(records))
+	for _, record := range records {
+		var event events.Event
+		if err := json.Unmarshal(record.Event, &event); err != nil {
+			return 0, err
+		}
+
+		if err := publish(ctx, event); err != nil {
+			if err := retryOutbox(ctx, tx, record, err, retryDelay(record.Attempts+1)); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		published = append(published, record.ID)
+	}
+
+	if len(published) > 0 {
+		query, args, err := sq.
+			Delete(outboxTable).
+			Where(
+				sq.Eq{
+					ID: published,
+				},
+			).
+			PlaceholderFormat(sq.Dollar).
+			ToSql()
+		if err != nil {
+			return 0, err
+		}
+
+		if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(records), tx.Commit()
+}
+
+func retryOutbox(ctx context.Context, tx *sqlx.Tx, record outboxRecord, cause error, delay time.Duration) error {
+	query, args, err := sq.
+		Update(outboxTable).
+		Set(Attempts, record.Attempts+1).
+		Set(AvailableAt, time.Now().Add(delay)).
+		Set(LastError, cause.Error()).
+		Where(
+			sq.Eq{
+				ID: record.ID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (p *PostgresRepository) EventProcessed(ctx context.Context, consumer, eventID string) (bool, error) {
+	var count int64
+
+	query, args, err := sq.
+		Select("COUNT(*)").
+		From(processedEventsTable).
+		Where(
+			sq.Eq{
+				Consumer: consumer,
+				EventID:  eventID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	err = p.db.GetContext(ctx, &count, query, args...)
+	return count > 0, err
+}
+
+func (p *PostgresRepository) MarkEventProcessed(ctx context.Context, consumer, eventID string) error {
+	query, args, err := sq.
+		Insert(processedEventsTable).
+		Columns(Consumer, EventID, ProcessedAt).
+		Values(consumer, eventID, time.Now()).
+		Suffix("ON CONFLICT DO NOTHING").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (p *PostgresRepository) DeleteProcessedEvents(ctx context.Context, processedBefore time.Time) (int64, error) {
+	query, args, err := sq.
+		Delete(processedEventsTable).
+		Where(
+			sq.Lt{
+				ProcessedAt: processedBefore,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
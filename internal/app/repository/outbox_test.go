@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"task-manager-backend/internal/app/events"
+	"testing"
+	"time"
+)
+
+func TestRelayOutbox(t *testing.T) {
+	p := newTestRepository(t)
+	ctx := context.Background()
+
+	user := newTestUser()
+	created := events.NewEvent(events.ConfirmationRequested, user.ID, nil, nil)
+	failing := events.NewEvent(events.ConfirmationRequested, user.ID, nil, nil)
+	if err := p.CreateUser(ctx, user, created, failing); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	// The insert fails on the duplicate email, so its event is never relayed.
+	rolledBack := events.NewEvent(events.ConfirmationRequested, user.ID, nil, nil)
+	duplicate := newTestUser()
+	duplicate.Email = user.Email
+	if err := p.CreateUser(ctx, duplicate, rolledBack); err == nil {
+		t.Fatal("user with a duplicate email was created")
+	}
+
+	published := make(map[string]int)
+	attempts := make([]int, 0)
+	relay := func() {
+		t.Helper()
+		_, err := p.RelayOutbox(ctx, 1000, func(_ context.Context, event events.Event) error {
+			published[event.ID]++
+			if event.ID == failing.ID && published[event.ID] == 1 {
+				return errors.New("failing")
+			}
+			return nil
+		}, func(n int) time.Duration {
+			attempts = append(attempts, n)
+			// Available again right away, so the next relay retries it.
+			return -time.Second
+		})
+		if err != nil {
+			t.Fatalf("RelayOutbox: %v", err)
+		}
+	}
+
+	relay()
+	if published[created.ID] != 1 || published[failing.ID] != 1 {
+		t.Fatalf("published %v, want both events once", published)
+	}
+	if len(attempts) != 1 || attempts[0] != 1 {
+		t.Fatalf("retry delays asked for attempts %v, want [1]", attempts)
+	}
+
+	relay()
+	if published[created.ID] != 1 {
+		t.Fatal("published event was relayed again")
+	}
+	if published[failing.ID] != 2 {
+		t.Fatal("failed event was not relayed again")
+	}
+	if published[rolledBack.ID] != 0 {
+		t.Fatal("event of a rolled back change was relayed")
+	}
+
+	relay()
+	if published[failing.ID] != 2 {
+		t.Fatal("event was relayed again after it was published")
+	}
+}
+
+func TestProcessedEvents(t *testing.T) {
+	p := newTestRepository(t)
+	ctx := context.Background()
+	eventID := uuid.NewString()
+
+	for i := 0; i < 2; i++ {
+		if err := p.MarkEventProcessed(ctx, "test", eventID); err != nil {
+			t.Fatalf("MarkEventProcessed: %v", err)
+		}
+	}
+	if processed, err := p.EventProcessed(ctx, "test", eventID); err != nil || !processed {
+		t.Fatalf("EventProcessed: %v, %v, want true", processed, err)
+	}
+	if processed, err := p.EventProcessed(ctx, "other", eventID); err != nil || processed {
+		t.Fatalf("EventProcessed for another consumer: %v, %v, want false", processed, err)
+	}
+
+	if _, err := p.DeleteProcessedEvents(ctx, time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("DeleteProcessedEvents: %v", err)
+	}
+	if processed, err := p.EventProcessed(ctx, "test", eventID); err != nil || processed {
+		t.Fatalf("EventProcessed after pruning: %v, %v, want false", processed, err)
+	}
+}
@@ -1,23 +1,72 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
-	"log"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
 	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/health"
+	"task-manager-backend/internal/app/tracing"
 )
 
-func NewPostgresRepository(cfg config.ServiceConfiguration) *PostgresRepository {
-	db, err := sqlx.Connect("postgres", cfg.PostgresDSN.String())
+// uniqueViolation is the SQLSTATE of an insert or update that breaks a
+// unique index.
+const uniqueViolation = "23505"
+
+// NewPostgresRepository connects to Postgres, retrying for a while if it is
+// not up yet. If it stays down the service starts anyway and the pool
+// connects once it is back. The pool is closed when the app stops, after
+// the hooks of everything built on it.
+func NewPostgresRepository(lifecycle fx.Lifecycle, cfg config.ServiceConfiguration, registerer prometheus.Registerer, logger *zap.Logger) (*PostgresRepository, error) {
+	connector, err := pq.NewConnector(cfg.PostgresDSN.String())
 	if err != nil {
-		log.Fatalln(err)
+		return nil, err
+	}
+	db := sqlx.NewDb(sql.OpenDB(tracing.WrapConnector(connector, "postgresql")), "postgres")
+	if err = registerer.Register(collectors.NewDBStatsCollector(db.DB, "postgres")); err != nil {
+		return nil, err
+	}
+	if health.WaitFor(logger, "postgres", cfg.Health.StartupTimeout, db.PingContext) == nil {
+		warnBypassingRLS(db, logger)
 	}
 
+	lifecycle.Append(
+		fx.Hook{
+			OnStop: func(ctx context.Context) error {
+				return db.Close()
+			},
+		})
+
 	return &PostgresRepository{
 		db: db,
+	}, nil
+}
+
+// warnBypassingRLS complains when the service connects as a role that row
+// level security does not apply to, which leaves tenant isolation to the
+// queries alone.
+func warnBypassingRLS(db *sqlx.DB, logger *zap.Logger) {
+	var role string
+	var bypasses bool
+	err := db.QueryRowx("SELECT rolname, rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&role, &bypasses)
+	if err != nil {
+		logger.Warn("Postgres: Cant check the role of the connection", zap.Error(err))
+		return
+	}
+	if bypasses {
+		logger.Warn("Postgres: Role bypasses row level security, connect as task_manager instead", zap.String("role", role))
 	}
 }
 
+func (p *PostgresRepository) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
 type PostgresRepository struct {
 	db *sqlx.DB
 }
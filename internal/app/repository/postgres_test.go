@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"os"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/models/workspaces"
+	"testing"
+)
+
+// testDSNEnv names a migrated database the integration tests may write to,
+// connected to as task_manager so row level security applies as it does to
+// the service. Without it they are skipped.
+const testDSNEnv = "TM_TEST_POSTGRES_DSN"
+
+func newTestRepository(t *testing.T) *PostgresRepository {
+	t.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &PostgresRepository{db: db}
+}
+
+func newTestUser() users.User {
+	return users.NewUser(users.Email(uuid.NewString()+"@example.com"), "", users.StatusSimple)
+}
+
+// newTestWorkspace creates a workspace with a fresh owner.
+func newTestWorkspace(t *testing.T, p *PostgresRepository) workspaces.Workspace {
+	t.Helper()
+	ctx := context.Background()
+	owner := newTestUser()
+	if err := p.CreateUser(ctx, owner); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	workspace := workspaces.NewWorkspace("test", owner.ID)
+	if err := p.CreateWorkspace(ctx, workspace); err != nil {
+		t.Fatalf("CreateWorkspace: %v", err)
+	}
+	return workspace
+}
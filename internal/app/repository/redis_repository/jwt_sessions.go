@@ -1,6 +1,7 @@
 package redis_repository
 
 import (
+	"context"
 	"strconv"
 	"task-manager-backend/internal/app/models/users"
 	"time"
@@ -10,14 +11,17 @@ const (
 	RefreshTemplateKey = "refresh_"
 )
 
-func (r *RedisRepository) CashRefreshToken(uid users.ID, refresh string, at time.Duration) error {
-	return r.db.Set(RefreshTemplateKey+refresh, strconv.Itoa(int(uid)), at).Err()
+func (r *RedisRepository) CashRefreshToken(ctx context.Context, uid users.ID, refresh string, at time.Duration) error {
+	return r.client(ctx).Set(RefreshTemplateKey+refresh, strconv.Itoa(int(uid)), at).Err()
 }
 
-func (r *RedisRepository) GetUserIDByRefreshToken(refresh string) (string, error) {
-	return r.db.Get(RefreshTemplateKey + refresh).Result()
+// GetUserIDByRefreshToken returns an empty ID for unknown and expired
+// tokens.
+func (r *RedisRepository) GetUserIDByRefreshToken(ctx context.Context, refresh string) (string, error) {
+	userID, err := r.client(ctx).Get(RefreshTemplateKey + refresh).Result()
+	return userID, redisError(err)
 }
 
-func (r *RedisRepository) DeleteSession(refresh string) error {
-	return r.db.Del(RefreshTemplateKey + refresh).Err()
+func (r *RedisRepository) DeleteSession(ctx context.Context, refresh string) error {
+	return r.client(ctx).Del(RefreshTemplateKey + refresh).Err()
 }
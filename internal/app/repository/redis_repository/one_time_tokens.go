@@ -0,0 +1,94 @@
+package redis_repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-redis/redis"
+	"task-manager-backend/internal/app/models/tokens"
+)
+
+const (
+	oneTimeTokenTemplateKey = "one_time_token:"
+)
+
+func oneTimeTokenKey(purpose tokens.Purpose, token string) string {
+	return oneTimeTokenTemplateKey + string(purpose) + ":" + token
+}
+
+func (r *RedisRepository) CreateOneTimeToken(ctx context.Context, token tokens.OneTimeToken) error {
+	ttl := token.TTL()
+	if ttl <= 0 {
+		return errors.New("CreateOneTimeToken err: token already expired")
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	created, err := r.client(ctx).SetNX(oneTimeTokenKey(token.Purpose, token.Token), data, ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !created {
+		return errors.New("CreateOneTimeToken err: token already exists")
+	}
+
+	return nil
+}
+
+// GetOneTimeToken reads the token without redeeming it, so a request can be
+// checked before the token is spent.
+func (r *RedisRepository) GetOneTimeToken(ctx context.Context, purpose tokens.Purpose, token string) (tokens.OneTimeToken, error) {
+	data, err := r.client(ctx).Get(oneTimeTokenKey(purpose, token)).Result()
+	if err == redis.Nil {
+		return tokens.OneTimeToken{}, errors.New("GetOneTimeToken err: token not found")
+	}
+	if err != nil {
+		return tokens.OneTimeToken{}, err
+	}
+
+	var result tokens.OneTimeToken
+	if err = json.Unmarshal([]byte(data), &result); err != nil {
+		return tokens.OneTimeToken{}, errors.New("GetOneTimeToken err: bad token payload")
+	}
+	result.Token = token
+
+	if result.Purpose != purpose || result.Expired() {
+		return tokens.OneTimeToken{}, errors.New("GetOneTimeToken err: token not found")
+	}
+
+	return result, nil
+}
+
+// ConsumeOneTimeToken reads and deletes the token in a single transaction,
+// so concurrent requests cannot redeem it twice.
+func (r *RedisRepository) ConsumeOneTimeToken(ctx context.Context, purpose tokens.Purpose, token string) (tokens.OneTimeToken, error) {
+	key := oneTimeTokenKey(purpose, token)
+
+	var get *redis.StringCmd
+	_, err := r.client(ctx).TxPipelined(func(pipe redis.Pipeliner) error {
+		get = pipe.Get(key)
+		pipe.Del(key)
+		return nil
+	})
+	if err == redis.Nil {
+		return tokens.OneTimeToken{}, errors.New("ConsumeOneTimeToken err: token not found")
+	}
+	if err != nil {
+		return tokens.OneTimeToken{}, err
+	}
+
+	var result tokens.OneTimeToken
+	if err = json.Unmarshal([]byte(get.Val()), &result); err != nil {
+		return tokens.OneTimeToken{}, errors.New("ConsumeOneTimeToken err: bad token payload")
+	}
+	result.Token = token
+
+	if result.Purpose != purpose || result.Expired() {
+		return tokens.OneTimeToken{}, errors.New("ConsumeOneTimeToken err: token not found")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,30 @@
+package redis_repository
+
+import (
+	"context"
+	"task-manager-backend/internal/app/models/users"
+	"time"
+)
+
+const (
+	rateTemplateKey = "rate:"
+)
+
+// IncrRate counts requests for the action made on behalf of the email within
+// a fixed window that starts with the first request.
+func (r *RedisRepository) IncrRate(ctx context.Context, action string, email users.Email, window time.Duration) (int64, error) {
+	key := rateTemplateKey + action + ":" + string(email)
+	client := r.client(ctx)
+	count, err := client.Incr(key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	if count == 1 {
+		if err = client.Expire(key, window).Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
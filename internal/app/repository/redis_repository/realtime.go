@@ -0,0 +1,82 @@
+package redis_repository
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis"
+	"task-manager-backend/internal/app/events"
+	"time"
+)
+
+const (
+	realtimeStreamKey  = "realtime_events"
+	realtimeStreamLen  = 10000
+	realtimeEventField = "event"
+)
+
+// AppendRealtimeEvent adds the event to a capped stream shared by all API
+// instances and returns the ID the stream assigned to it.
+func (r *RedisRepository) AppendRealtimeEvent(ctx context.Context, event events.Event) (string, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+
+	return r.client(ctx).XAdd(&redis.XAddArgs{
+		Stream:       realtimeStreamKey,
+		MaxLenApprox: realtimeStreamLen,
+		Values:       map[string]interface{}{realtimeEventField: data},
+	}).Result()
+}
+
+// LastRealtimeEventID returns the ID of the newest event, or "0-0" when the
+// stream is empty.
+func (r *RedisRepository) LastRealtimeEventID(ctx context.Context) (string, error) {
+	messages, err := r.client(ctx).XRevRangeN(realtimeStreamKey, "+", "-", 1).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(messages) == 0 {
+		return "0-0", nil
+	}
+	return messages[0].ID, nil
+}
+
+// ReadRealtimeEvents returns events added after the given ID, waiting up to
+// block for new ones.
+func (r *RedisRepository) ReadRealtimeEvents(ctx context.Context, after string, count int64, block time.Duration) ([]events.Record, error) {
+	streams, err := r.client(ctx).XRead(&redis.XReadArgs{
+		Streams: []string{realtimeStreamKey, after},
+		Count:   count,
+		Block:   block,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []events.Record
+	for _, stream := range streams {
+		records = append(records, decodeRealtimeMessages(stream.Messages)...)
+	}
+	return records, nil
+}
+
+func decodeRealtimeMessages(messages []redis.XMessage) []events.Record {
+	records := make([]events.Record, 0, len(messages))
+	for _, message := range messages {
+		data, ok := message.Values[realtimeEventField].(string)
+		if !ok {
+			continue
+		}
+
+		var event events.Event
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			continue
+		}
+		records = append(records, events.Record{ID: message.ID, Event: event})
+	}
+	return records
+}
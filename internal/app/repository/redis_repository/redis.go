@@ -1,8 +1,21 @@
 package redis_repository
 
-import "github.com/go-redis/redis"
+import (
+	"context"
+	"github.com/go-redis/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"task-manager-backend/internal/app/metrics"
+	"task-manager-backend/internal/app/tracing"
+	"time"
+)
 
-func NewRedisRepo(addr string, passwd string, dbID int) (*RedisRepository, error) {
+const tracerName = "task-manager-backend/internal/app/repository/redis_repository"
+
+func NewRedisRepo(addr string, passwd string, dbID int, registerer prometheus.Registerer) (*RedisRepository, error) {
 	testClient := &RedisRepository{
 		db: redis.NewClient(&redis.Options{
 			Addr:     addr,
@@ -10,6 +23,7 @@ func NewRedisRepo(addr string, passwd string, dbID int) (*RedisRepository, error
 			DB:       dbID,
 		}),
 	}
+	instrument(testClient.db, registerer)
 
 	_, err := testClient.db.Ping().Result()
 
@@ -17,6 +31,96 @@ func NewRedisRepo(addr string, passwd string, dbID int) (*RedisRepository, error
 
 }
 
+// instrument records the latency of every command, and of pipelines as a
+// whole. A missing key is a result, not an error.
+func instrument(client *redis.Client, registerer prometheus.Registerer) {
+	duration := promauto.With(registerer).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "redis",
+		Name:      "command_duration_seconds",
+		Help:      "Latency of Redis commands by command and result.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 5},
+	}, []string{"command", "result"})
+
+	observe := func(command string, started time.Time, err error) {
+		result := "success"
+		if redisError(err) != nil {
+			result = "failure"
+		}
+		duration.WithLabelValues(command, result).Observe(time.Since(started).Seconds())
+	}
+
+	client.WrapProcess(func(process func(redis.Cmder) error) func(redis.Cmder) error {
+		return func(cmd redis.Cmder) error {
+			started := time.Now()
+			err := process(cmd)
+			observe(cmd.Name(), started, err)
+			return err
+		}
+	})
+	client.WrapProcessPipeline(func(process func([]redis.Cmder) error) func([]redis.Cmder) error {
+		return func(cmds []redis.Cmder) error {
+			started := time.Now()
+			err := process(cmds)
+			observe("pipeline", started, err)
+			return err
+		}
+	})
+}
+
 type RedisRepository struct {
 	db *redis.Client
 }
+
+// client returns a client bound to ctx whose commands are traced as
+// children of the span in it.
+func (r *RedisRepository) client(ctx context.Context) *redis.Client {
+	client := r.db.WithContext(ctx)
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return client
+	}
+
+	start := func(operation string) trace.Span {
+		_, span := otel.Tracer(tracerName).Start(ctx, operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemRedis,
+				semconv.DBOperation(operation),
+			))
+		return span
+	}
+	client.WrapProcess(func(process func(redis.Cmder) error) func(redis.Cmder) error {
+		return func(cmd redis.Cmder) error {
+			span := start(cmd.Name())
+			err := process(cmd)
+			tracing.End(span, redisError(err))
+			return err
+		}
+	})
+	client.WrapProcessPipeline(func(process func([]redis.Cmder) error) func([]redis.Cmder) error {
+		return func(cmds []redis.Cmder) error {
+			span := start("pipeline")
+			err := process(cmds)
+			tracing.End(span, redisError(err))
+			return err
+		}
+	})
+	return client
+}
+
+// redisError hides redis.Nil, which reports a missing key rather than a
+// failure.
+func redisError(err error) error {
+	if err == redis.Nil {
+		return nil
+	}
+	return err
+}
+
+func (r *RedisRepository) Ping(ctx context.Context) error {
+	return r.client(ctx).Ping().Err()
+}
+
+func (r *RedisRepository) Close() error {
+	return r.db.Close()
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"strconv"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/models/workspaces"
+)
+
+const (
+	workspaceSetting = "app.workspace_id"
+	userSetting      = "app.user_id"
+	// deliveryWorkerSetting lets the webhook worker see the pending
+	// deliveries of every workspace.
+	deliveryWorkerSetting = "app.delivery_worker"
+)
+
+// inWorkspace runs fn in a transaction bound to the workspace. Row level
+// security policies compare workspace_id with the app.workspace_id setting,
+// so a query that forgets its workspace filter still cannot reach rows of
+// another tenant. All access to workspace-scoped tables goes through here.
+func (p *PostgresRepository) inWorkspace(ctx context.Context, workspaceID workspaces.ID, fn func(tx *sqlx.Tx) error) error {
+	return p.withSetting(ctx, workspaceSetting, workspaceSettingValue(workspaceID), fn)
+}
+
+func workspaceSettingValue(workspaceID workspaces.ID) string {
+	return strconv.FormatUint(uint64(workspaceID), 10)
+}
+
+// asUser runs fn in a transaction that may only see the user's own
+// memberships, e.g. to list the workspaces the user belongs to.
+func (p *PostgresRepository) asUser(ctx context.Context, userID users.ID, fn func(tx *sqlx.Tx) error) error {
+	return p.withSetting(ctx, userSetting, strconv.FormatUint(uint64(userID), 10), fn)
+}
+
+// asDeliveryWorker runs fn in a transaction that sees the webhook deliveries
+// of all workspaces. It is only for claiming them; the outcome of an attempt
+// is stored in the delivery's own workspace.
+func (p *PostgresRepository) asDeliveryWorker(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return p.withSetting(ctx, deliveryWorkerSetting, "on", fn)
+}
+
+func (p *PostgresRepository) withSetting(ctx context.Context, name, value string, fn func(tx *sqlx.Tx) error) error {
+	return p.inTx(ctx, func(tx *sqlx.Tx) error {
+		if err := setSetting(ctx, tx, name, value); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// setSetting binds the rest of the transaction to the setting.
+func setSetting(ctx context.Context, tx *sqlx.Tx, name, value string) error {
+	_, err := tx.ExecContext(ctx, "SELECT set_config($1, $2, true)", name, value)
+	return err
+}
+
+func (p *PostgresRepository) inTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"task-manager-backend/internal/app/events"
 	"task-manager-backend/internal/app/models/users"
+	"time"
 )
 
 const (
@@ -15,17 +18,46 @@ const (
 	Status     = "status"
 	Confirmed  = "confirmed"
 	RegData    = "reg_date"
+
+	DisplayName = "display_name"
+	Avatar      = "avatar"
+	Timezone    = "timezone"
+	Locale      = "locale"
+	Bio         = "bio"
+	LastLogin   = "last_login"
+
+	LegacyPassword = "legacy_password"
 )
 
-func (p *PostgresRepository) CreateUser(ctx context.Context, user users.User) error {
+var userColumns = []string{
+	ID, Email, PwdHash, Status, Confirmed, RegData,
+	DisplayName, Avatar, Timezone, Locale, Bio, LastLogin, LegacyPassword,
+}
+
+// CreateUser stores the user together with the events announcing it.
+func (p *PostgresRepository) CreateUser(ctx context.Context, user users.User, announce ...events.Event) error {
+	return p.inTx(ctx, func(tx *sqlx.Tx) error {
+		if err := insertUser(ctx, tx, user); err != nil {
+			return err
+		}
+		return appendOutbox(ctx, tx, announce)
+	})
+}
+
+func insertUser(ctx context.Context, tx *sqlx.Tx, user users.User) error {
 	query, args, err := sq.
 		Insert(usersTable).
-		Columns(ID, Email, PwdHash, Status).
+		Columns(ID, Email, PwdHash, Status, Confirmed, RegData, Timezone, Locale, LegacyPassword).
 		Values(
 			user.ID,
 			user.Email,
 			user.PwdHash,
 			user.Status,
+			user.Confirmed,
+			user.RegDate,
+			user.Timezone,
+			user.Locale,
+			user.LegacyPassword,
 		).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
@@ -34,7 +66,7 @@ func (p *PostgresRepository) CreateUser(ctx context.Context, user users.User) er
 		return err
 	}
 
-	_, err = p.db.ExecContext(ctx, query, args...)
+	_, err = tx.ExecContext(ctx, query, args...)
 	return err
 }
 
@@ -42,7 +74,7 @@ func (p *PostgresRepository) GetUserByEmail(ctx context.Context, email users.Ema
 	var user users.User
 
 	query, args, err := sq.
-		Select(ID, Email, PwdHash, Status, Confirmed).
+		Select(userColumns...).
 		From(usersTable).
 		Where(
 			sq.Eq{
@@ -66,7 +98,7 @@ func (p *PostgresRepository) GetUserByUserID(ctx context.Context, userID users.I
 	var user users.User
 
 	query, args, err := sq.
-		Select(ID, Email, PwdHash, Status, Confirmed).
+		Select(userColumns...).
 		From(usersTable).
 		Where(
 			sq.Eq{
@@ -107,10 +139,101 @@ func (p *PostgresRepository) ConfirmUser(ctx context.Context, userID users.ID) e
 
 }
 
-func (p *PostgresRepository) ChangePasswordByUserID(ctx context.Context, userID users.ID, newPassword string) error {
+// ChangePasswordByUserID stores a new password hash. legacy tells whether
+// it is a hash of the client-side pwd_hash of the v1 API.
+func (p *PostgresRepository) ChangePasswordByUserID(ctx context.Context, userID users.ID, newPassword string, legacy bool) error {
 	query, args, err := sq.
 		Update(usersTable).
 		Set(PwdHash, newPassword).
+		Set(LegacyPassword, legacy).
+		Where(
+			sq.Eq{
+				ID: userID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (p *PostgresRepository) DeleteUnconfirmedUsers(ctx context.Context, registeredBefore time.Time) (int64, error) {
+	query, args, err := sq.
+		Delete(usersTable).
+		Where(
+			sq.And{
+				sq.Eq{Confirmed: false},
+				sq.Lt{RegData: registeredBefore},
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (p *PostgresRepository) UpdateProfile(ctx context.Context, userID users.ID, update users.ProfileUpdate) error {
+	values := make(map[string]interface{})
+	if update.DisplayName != nil {
+		values[DisplayName] = *update.DisplayName
+	}
+	if update.Timezone != nil {
+		values[Timezone] = *update.Timezone
+	}
+	if update.Locale != nil {
+		values[Locale] = *update.Locale
+	}
+	if update.Bio != nil {
+		values[Bio] = *update.Bio
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	query, args, err := sq.
+		Update(usersTable).
+		SetMap(values).
+		Where(
+			sq.Eq{
+				ID: userID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (p *PostgresRepository) SetAvatar(ctx context.Context, userID users.ID, avatar string) error {
+	return p.setUserColumn(ctx, userID, Avatar, avatar)
+}
+
+func (p *PostgresRepository) UpdateLastLogin(ctx context.Context, userID users.ID, at time.Time) error {
+	return p.setUserColumn(ctx, userID, LastLogin, at)
+}
+
+func (p *PostgresRepository) setUserColumn(ctx context.Context, userID users.ID, column string, value interface{}) error {
+	query, args, err := sq.
+		Update(usersTable).
+		Set(column, value).
 		Where(
 			sq.Eq{
 				ID: userID,
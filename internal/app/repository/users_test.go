@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"task-manager-backend/internal/app/models/users"
+	"testing"
+	"time"
+)
+
+func TestDeleteUnconfirmedUsers(t *testing.T) {
+	p := newTestRepository(t)
+	ctx := context.Background()
+	cutoff := time.Now().Add(-time.Hour)
+
+	stale := newTestUser()
+	stale.RegDate = cutoff.Add(-time.Minute)
+	recent := newTestUser()
+	confirmed := newTestUser()
+	confirmed.RegDate = stale.RegDate
+	confirmed.Confirmed = true
+	for _, user := range []users.User{stale, recent, confirmed} {
+		if err := p.CreateUser(ctx, user); err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+	}
+
+	if _, err := p.DeleteUnconfirmedUsers(ctx, cutoff); err != nil {
+		t.Fatalf("DeleteUnconfirmedUsers: %v", err)
+	}
+	for _, tt := range []struct {
+		user users.User
+		kept bool
+	}{
+		{stale, false},
+		{recent, true},
+		{confirmed, true},
+	} {
+		got, err := p.GetUserByEmail(ctx, tt.user.Email)
+		if err != nil {
+			t.Fatalf("GetUserByEmail: %v", err)
+		}
+		if kept := got.Email == tt.user.Email; kept != tt.kept {
+			t.Errorf("user registered at %s, confirmed %v: kept %v, want %v", tt.user.RegDate, tt.user.Confirmed, kept, tt.kept)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"strings"
+	"task-manager-backend/internal/app/models/webhooks"
+	"task-manager-backend/internal/app/models/workspaces"
+	"time"
+)
+
+const (
+	webhooksTable          = "webhooks"
+	webhookDeliveriesTable = "webhook_deliveries"
+	URL                    = "url"
+	Secret                 = "secret"
+	EventTypes             = "event_types"
+	Active                 = "active"
+	Failures               = "failures"
+	WebhookID              = "webhook_id"
+	Attempts               = "attempts"
+	NextAttemptAt          = "next_attempt_at"
+	ResponseStatus         = "response_status"
+	LastError              = "last_error"
+	UpdatedAt              = "updated_at"
+)
+
+var (
+	webhookColumns  = []string{ID, WorkspaceID, URL, Secret, EventTypes, Active, Failures, CreatedAt}
+	deliveryColumns = []string{ID, WebhookID, WorkspaceID, EventType, Payload, Status, Attempts, NextAttemptAt, ResponseStatus, LastError, CreatedAt, UpdatedAt}
+)
+
+func (p *PostgresRepository) CreateWebhook(ctx context.Context, webhook webhooks.Webhook) error {
+	query, args, err := sq.
+		Insert(webhooksTable).
+		Columns(webhookColumns...).
+		Values(
+			webhook.ID,
+			webhook.WorkspaceID,
+			webhook.URL,
+			webhook.Secret,
+			webhook.EventTypes,
+			webhook.Active,
+			webhook.Failures,
+			webhook.CreatedAt,
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	return p.inWorkspace(ctx, webhook.WorkspaceID, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	})
+}
+
+func (p *PostgresRepository) GetWebhooks(ctx context.Context, workspaceID workspaces.ID) ([]webhooks.Webhook, error) {
+	result := make([]webhooks.Webhook, 0)
+
+	query, args, err := sq.
+		Select(webhookColumns...).
+		From(webhooksTable).
+		Where(
+			sq.Eq{
+				WorkspaceID: workspaceID,
+			},
+		).
+		OrderBy(CreatedAt).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return result, err
+	}
+
+	err = p.inWorkspace(ctx, workspaceID, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &result, query, args...)
+	})
+	return result, err
+}
+
+func (p *PostgresRepository) GetWebhook(ctx context.Context, workspaceID workspaces.ID, webhookID webhooks.ID) (webhooks.Webhook, error) {
+	var webhook webhooks.Webhook
+
+	query, args, err := sq.
+		Select(webhookColumns...).
+		From(webhooksTable).
+		Where(
+			sq.Eq{
+				ID:          webhookID,
+				WorkspaceID: workspaceID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return webhook, err
+	}
+
+	err = p.inWorkspace(ctx, workspaceID, func(tx *sqlx.Tx) error {
+		return tx.GetContext(ctx, &webhook, query, args...)
+	})
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return webhook, err
+}
+
+func (p *PostgresRepository) DeleteWebhook(ctx context.Context, workspaceID workspaces.ID, webhookID webhooks.ID) (bool, error) {
+	query, args, err := sq.
+		Delete(webhooksTable).
+		Where(
+			sq.Eq{
+				ID:          webhookID,
+				WorkspaceID: workspaceID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return false, err
+	}
+
+	var affected int64
+	err = p.inWorkspace(ctx, workspaceID, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		affected, err = result.RowsAffected()
+		return err
+	})
+	return affected > 0, err
+}
+
+// SetWebhookActive enables or disables the webhook. Enabling it also forgets
+// the failures that got it disabled.
+func (p *PostgresRepository) SetWebhookActive(ctx context.Context, workspaceID workspaces.ID, webhookID webhooks.ID, active bool) error {
+	update := sq.
+		Update(webhooksTable).
+		Set(Active, active)
+	if active {
+		update = update.Set(Failures, 0)
+	}
+
+	query, args, err := update.
+		Where(
+			sq.Eq{
+				ID:          webhookID,
+				WorkspaceID: workspaceID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	return p.inWorkspace(ctx, workspaceID, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	})
+}
+
+// RecordWebhookFailure counts one more failed delivery in a row and returns
+// the new count.
+func (p *PostgresRepository) RecordWebhookFailure(ctx context.Context, workspaceID workspaces.ID, webhookID webhooks.ID) (int, error) {
+	var failures int
+
+	query, args, err := sq.
+		Update(webhooksTable).
+		Set(Failures, sq.Expr(Failures+" + 1")).
+		Where(
+			sq.Eq{
+				ID:          webhookID,
+				WorkspaceID: workspaceID,
+			},
+		).
+		Suffix("RETURNING " + Failures).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return failures, err
+	}
+
+	err = p.inWorkspace(ctx, workspaceID, func(tx *sqlx.Tx) error {
+		return tx.GetContext(ctx, &failures, query, args...)
+	})
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return failures, err
+}
+
+func (p *PostgresRepository) ResetWebhookFailures(ctx context.Context, workspaceID workspaces.ID, webhookID webhooks.ID) error {
+	query, args, err := sq.
+		Update(webhooksTable).
+		Set(Failures, 0).
+		Where(
+			sq.And{
+				sq.Eq{
+					ID:          webhookID,
+					WorkspaceID: workspaceID,
+				},
+				sq.NotEq{
+					Failures: 0,
+				},
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	return p.inWorkspace(ctx, workspaceID, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	})
+}
+
+func (p *PostgresRepository) CreateWebhookDelivery(ctx context.Context, delivery webhooks.Delivery) error {
+	query, args, err := sq.
+		Insert(webhookDeliveriesTable).
+		Columns(deliveryColumns...).
+		Values(
+			delivery.ID,
+			delivery.WebhookID,
+			delivery.WorkspaceID,
+			delivery.EventType,
+			string(delivery.Payload),
+			delivery.Status,
+			delivery.Attempts,
+			delivery.NextAttemptAt,
+			delivery.ResponseStatus,
+			delivery.LastError,
+			delivery.CreatedAt,
+			delivery.UpdatedAt,
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	return p.inWorkspace(ctx, delivery.WorkspaceID, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	})
+}
+
+func (p *PostgresRepository) GetWebhookDeliveries(ctx context.Context, workspaceID workspaces.ID, webhookID webhooks.ID, limit, offset uint64) ([]webhooks.Delivery, error) {
+	result := make([]webhooks.Delivery, 0)
+
+	query, args, err := sq.
+		Select(deliveryColumns...).
+		From(webhookDeliveriesTable).
+		Where(
+			sq.Eq{
+				WebhookID:   webhookID,
+				WorkspaceID: workspaceID,
+			},
+		).
+		OrderBy(CreatedAt + " DESC").
+		Limit(limit).
+		Offset(offset).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return result, err
+	}
+
+	err = p.inWorkspace(ctx, workspaceID, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &result, query, args...)
+	})
+	return result, err
+}
+
+func (p *PostgresRepository) GetWebhookDelivery(ctx context.Context, workspaceID workspaces.ID, webhookID webhooks.ID, deliveryID webhooks.DeliveryID) (webhooks.Delivery, error) {
+	var delivery webhooks.Delivery
+
+	query, args, err := sq.
+		Select(deliveryColumns...).
+		From(webhookDeliveriesTable).
+		Where(
+			sq.Eq{
+				ID:          deliveryID,
+				WebhookID:   webhookID,
+				WorkspaceID: workspaceID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return delivery, err
+	}
+
+	err = p.inWorkspace(ctx, workspaceID, func(tx *sqlx.Tx) error {
+		return tx.GetContext(ctx, &delivery, query, args...)
+	})
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return delivery, err
+}
+
+// ClaimWebhookDeliveries takes up to limit pending deliveries that are due
+// and counts the attempt. Their next attempt is pushed back by lease, so if
+// the worker dies mid-delivery another one picks them up later. SKIP LOCKED
+// lets several instances drain the queue without waiting on each other.
+func (p *PostgresRepository) ClaimWebhookDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]webhooks.Delivery, error) {
+	result := make([]webhooks.Delivery, 0)
+	now := time.Now()
+
+	due, dueArgs, err := sq.
+		Select(ID).
+		From(webhookDeliveriesTable).
+		Where(
+			sq.And{
+				sq.Eq{
+					Status: webhooks.Pending,
+				},
+				sq.LtOrEq{
+					NextAttemptAt: now,
+				},
+			},
+		).
+		OrderBy(NextAttemptAt).
+		Limit(limit).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return result, err
+	}
+
+	query, args, err := sq.
+		Update(webhookDeliveriesTable).
+		Set(Attempts, sq.Expr(Attempts+" + 1")).
+		Set(NextAttemptAt, now.Add(lease)).
+		Set(UpdatedAt, now).
+		Where(sq.Expr(ID+" IN ("+due+")", dueArgs...)).
+		Suffix("RETURNING " + strings.Join(deliveryColumns, ", ")).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return result, err
+	}
+
+	err = p.asDeliveryWorker(ctx, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &result, query, args...)
+	})
+	return result, err
+}
+
+// UpdateWebhookDelivery stores the outcome of an attempt.
+func (p *PostgresRepository) UpdateWebhookDelivery(ctx context.Context, delivery webhooks.Delivery) error {
+	query, args, err := sq.
+		Update(webhookDeliveriesTable).
+		SetMap(
+			map[string]interface{}{
+				Status:         delivery.Status,
+				NextAttemptAt:  delivery.NextAttemptAt,
+				ResponseStatus: delivery.ResponseStatus,
+				LastError:      delivery.LastError,
+				UpdatedAt:      delivery.UpdatedAt,
+			},
+		).
+		Where(
+			sq.Eq{
+				ID:          delivery.ID,
+				WorkspaceID: delivery.WorkspaceID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	return p.inWorkspace(ctx, delivery.WorkspaceID, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	})
+}
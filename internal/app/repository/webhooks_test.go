@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/webhooks"
+	"testing"
+	"time"
+)
+
+// claimedDeliveries claims what is due and keeps the deliveries among ids.
+// The queue is shared with whatever else is in the database.
+func claimedDeliveries(t *testing.T, ctx context.Context, p *PostgresRepository, lease time.Duration, ids ...webhooks.DeliveryID) map[webhooks.DeliveryID]webhooks.Delivery {
+	t.Helper()
+	claimed, err := p.ClaimWebhookDeliveries(ctx, 1000, lease)
+	if err != nil {
+		t.Fatalf("ClaimWebhookDeliveries: %v", err)
+	}
+	result := make(map[webhooks.DeliveryID]webhooks.Delivery)
+	for _, delivery := range claimed {
+		for _, id := range ids {
+			if delivery.ID == id {
+				result[id] = delivery
+			}
+		}
+	}
+	return result
+}
+
+func TestClaimWebhookDeliveries(t *testing.T) {
+	p := newTestRepository(t)
+	ctx := context.Background()
+	workspace := newTestWorkspace(t, p)
+
+	webhook, err := webhooks.NewWebhook(workspace.ID, "https://hooks.example.com", []string{string(events.TaskCreated)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.CreateWebhook(ctx, webhook); err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+
+	due := webhooks.NewDelivery(webhook, events.TaskCreated, []byte(`{}`))
+	later := webhooks.NewDelivery(webhook, events.TaskCreated, []byte(`{}`))
+	later.NextAttemptAt = time.Now().Add(time.Hour)
+	locked := webhooks.NewDelivery(webhook, events.TaskCreated, []byte(`{}`))
+	for _, delivery := range []webhooks.Delivery{due, later, locked} {
+		if err := p.CreateWebhookDelivery(ctx, delivery); err != nil {
+			t.Fatalf("CreateWebhookDelivery: %v", err)
+		}
+	}
+
+	// Another worker in the middle of claiming holds the row lock.
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if err := setSetting(ctx, tx, workspaceSetting, workspaceSettingValue(workspace.ID)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, "SELECT id FROM webhook_deliveries WHERE id = $1 FOR UPDATE", locked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	claimCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	claimed := claimedDeliveries(t, claimCtx, p, time.Minute, due.ID, later.ID, locked.ID)
+	if len(claimed) != 1 || claimed[due.ID].Attempts != 1 {
+		t.Fatalf("claimed %+v, want only the due delivery with one attempt", claimed)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	claimed = claimedDeliveries(t, ctx, p, -time.Second, due.ID, later.ID, locked.ID)
+	if len(claimed) != 1 || claimed[locked.ID].Attempts != 1 {
+		t.Fatalf("claimed %+v, want the unlocked delivery and not the leased one", claimed)
+	}
+
+	// The lease above already ran out, as if the worker died mid-delivery.
+	claimed = claimedDeliveries(t, ctx, p, time.Minute, locked.ID)
+	if claimed[locked.ID].Attempts != 2 {
+		t.Fatalf("claimed %+v, want the delivery again once its lease ran out", claimed)
+	}
+}
+
+func TestWebhookDeliveriesStayInTheirWorkspace(t *testing.T) {
+	p := newTestRepository(t)
+	ctx := context.Background()
+	workspace := newTestWorkspace(t, p)
+	other := newTestWorkspace(t, p)
+
+	webhook, err := webhooks.NewWebhook(workspace.ID, "https://hooks.example.com", []string{string(events.TaskCreated)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.CreateWebhook(ctx, webhook); err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	delivery := webhooks.NewDelivery(webhook, events.TaskCreated, []byte(`{}`))
+	if err := p.CreateWebhookDelivery(ctx, delivery); err != nil {
+		t.Fatalf("CreateWebhookDelivery: %v", err)
+	}
+
+	deliveries, err := p.GetWebhookDeliveries(ctx, workspace.ID, webhook.ID, 10, 0)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("GetWebhookDeliveries = %v, %v, want the delivery", deliveries, err)
+	}
+	deliveries, err = p.GetWebhookDeliveries(ctx, other.ID, webhook.ID, 10, 0)
+	if err != nil || len(deliveries) != 0 {
+		t.Fatalf("GetWebhookDeliveries from another workspace = %v, %v, want none", deliveries, err)
+	}
+	found, err := p.GetWebhookDelivery(ctx, other.ID, webhook.ID, delivery.ID)
+	if err != nil || found.ID != 0 {
+		t.Fatalf("GetWebhookDelivery from another workspace = %+v, %v, want none", found, err)
+	}
+
+	// Without the workspace filter the policy still hides the row.
+	var count int
+	err = p.inWorkspace(ctx, other.ID, func(tx *sqlx.Tx) error {
+		return tx.GetContext(ctx, &count, "SELECT count(*) FROM webhook_deliveries WHERE id = $1", delivery.ID)
+	})
+	if err != nil || count != 0 {
+		t.Fatalf("unfiltered count from another workspace = %d, %v, want 0", count, err)
+	}
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/models/workspaces"
+)
+
+const (
+	workspacesTable       = "workspaces"
+	workspaceMembersTable = "workspace_members"
+	Name                  = "name"
+	OwnerID               = "owner_id"
+	CreatedAt             = "created_at"
+	WorkspaceID           = "workspace_id"
+	UserID                = "user_id"
+	Role                  = "role"
+)
+
+func (p *PostgresRepository) CreateWorkspace(ctx context.Context, workspace workspaces.Workspace) error {
+	query, args, err := sq.
+		Insert(workspacesTable).
+		Columns(ID, Name, OwnerID, CreatedAt).
+		Values(
+			workspace.ID,
+			workspace.Name,
+			workspace.OwnerID,
+			workspace.CreatedAt,
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	owner := workspaces.Member{
+		WorkspaceID: workspace.ID,
+		UserID:      workspace.OwnerID,
+		Role:        workspaces.RoleOwner,
+	}
+
+	return p.inWorkspace(ctx, workspace.ID, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		return addMember(ctx, tx, owner)
+	})
+}
+
+func (p *PostgresRepository) GetWorkspacesByUserID(ctx context.Context, userID users.ID) ([]workspaces.Workspace, error) {
+	result := make([]workspaces.Workspace, 0)
+
+	query, args, err := sq.
+		Select("w."+ID, "w."+Name, "w."+OwnerID, "w."+CreatedAt).
+		From(workspacesTable + " w").
+		Join(workspaceMembersTable + " m ON m." + WorkspaceID + " = w." + ID).
+		Where(
+			sq.Eq{
+				"m." + UserID: userID,
+			},
+		).
+		OrderBy("w." + CreatedAt).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return result, err
+	}
+
+	err = p.asUser(ctx, userID, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &result, query, args...)
+	})
+	return result, err
+}
+
+func (p *PostgresRepository) GetWorkspaceMember(ctx context.Context, workspaceID workspaces.ID, userID users.ID) (workspaces.Member, error) {
+	var member workspaces.Member
+
+	query, args, err := sq.
+		Select(WorkspaceID, UserID, Role).
+		From(workspaceMembersTable).
+		Where(
+			sq.Eq{
+				WorkspaceID: workspaceID,
+				UserID:      userID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return member, err
+	}
+
+	err = p.inWorkspace(ctx, workspaceID, func(tx *sqlx.Tx) error {
+		return tx.GetContext(ctx, &member, query, args...)
+	})
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	return member, err
+}
+
+func (p *PostgresRepository) GetWorkspaceMembers(ctx context.Context, workspaceID workspaces.ID) ([]workspaces.Member, error) {
+	members := make([]workspaces.Member, 0)
+
+	query, args, err := sq.
+		Select(WorkspaceID, UserID, Role).
+		From(workspaceMembersTable).
+		Where(
+			sq.Eq{
+				WorkspaceID: workspaceID,
+			},
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return members, err
+	}
+
+	err = p.inWorkspace(ctx, workspaceID, func(tx *sqlx.Tx) error {
+		return tx.SelectContext(ctx, &members, query, args...)
+	})
+	return members, err
+}
+
+// AddWorkspaceMember stores the membership together with the events
+// announcing it.
+func (p *PostgresRepository) AddWorkspaceMember(ctx context.Context, member workspaces.Member, announce ...events.Event) error {
+	return p.inWorkspace(ctx, member.WorkspaceID, func(tx *sqlx.Tx) error {
+		if err := addMember(ctx, tx, member); err != nil {
+			return err
+		}
+		return appendOutbox(ctx, tx, announce)
+	})
+}
+
+func addMember(ctx context.Context, tx *sqlx.Tx, member workspaces.Member) error {
+	query, args, err := sq.
+		Insert(workspaceMembersTable).
+		Columns(WorkspaceID, UserID, Role).
+		Values(
+			member.WorkspaceID,
+			member.UserID,
+			member.Role,
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	return err
+}
@@ -2,30 +2,43 @@ package auth
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"golang.org/x/crypto/bcrypt"
-	"log"
-	"regexp"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
 	"strconv"
+	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/errs"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/logging"
+	"task-manager-backend/internal/app/models/invites"
+	"task-manager-backend/internal/app/models/tokens"
 	"task-manager-backend/internal/app/models/users"
-	"task-manager-backend/internal/app/service/mail"
+	"task-manager-backend/internal/app/models/workspaces"
 	"time"
 )
 
 const (
 	SessionTime    = 86400
 	RefreshKeyTime = 43200
+	RestoreKeyTime = 10800
+)
+
+const (
+	confirmRateAction = "confirm"
+	restoreRateAction = "restore"
 )
 
 var (
-	InvalidData      = errors.New("Invalid data")
-	UserAlreadyExist = errors.New("This email was registered before")
-	IncorrectCreds   = errors.New("Incorrect login or password")
-	NotFoundEmail    = errors.New("This email is not registered")
-	InvalidRefresh   = errors.New("Invalid token")
-	NonConfirmed     = errors.New("Registration has not been confirmed")
-	SamePassword     = errors.New("Same password")
+	InvalidData      = errs.New(errs.Invalid, errs.CodeInvalid, "Invalid data")
+	InvalidEmail     = errs.Validation(errs.FieldError{Field: "email", Code: "invalid_email", Message: "Invalid email"})
+	UserAlreadyExist = errs.New(errs.Conflict, "email_taken", "This email was registered before")
+	IncorrectCreds   = errs.New(errs.Unauthorized, "invalid_credentials", "Incorrect login or password")
+	NotFoundEmail    = errs.New(errs.NotFound, "email_not_found", "This email is not registered")
+	InvalidRefresh   = errs.New(errs.Unauthorized, "invalid_refresh_token", "Invalid refresh token")
+	InvalidToken     = errs.New(errs.Invalid, "invalid_token", "Invalid token")
+	Unauthenticated  = errs.New(errs.Unauthorized, "unauthenticated", "Authentication required")
+	NonConfirmed     = errs.New(errs.Forbidden, "email_not_confirmed", "Registration has not been confirmed")
+	SamePassword     = errs.New(errs.Invalid, "same_password", "Same password")
+	TooManyRequests  = errs.New(errs.TooManyRequests, "too_many_requests", "Too many requests, try again later")
 )
 
 type AuthData struct {
@@ -34,81 +47,263 @@ type AuthData struct {
 }
 
 type Repository interface {
-	CreateUser(context.Context, users.User) error
+	CreateUser(context.Context, users.User, ...events.Event) error
 	GetUserByEmail(context.Context, users.Email) (users.User, error)
 	ConfirmUser(context.Context, users.ID) error
-	ChangePasswordByUserID(context.Context, users.ID, string) error
+	ChangePasswordByUserID(context.Context, users.ID, string, bool) error
 	GetUserByUserID(context.Context, users.ID) (users.User, error)
-
-	CashRefreshToken(users.ID, string, time.Duration) error
-	GetUserIDByRefreshToken(string) (string, error)
-	DeleteSession(string) error
-	CreateRestoreRefresh(users.Email, users.ID, string) error
-	GetUserIDByRestoreRefresh(string) (users.ID, error)
+	DeleteUnconfirmedUsers(context.Context, time.Time) (int64, error)
+	UpdateLastLogin(context.Context, users.ID, time.Time) error
+	CreateInvite(context.Context, invites.Invite) error
+	GetInvite(context.Context, string) (invites.Invite, error)
+	CreateInvitedUser(context.Context, users.User, invites.Invite, ...events.Event) error
+	GetWorkspaceMember(context.Context, workspaces.ID, users.ID) (workspaces.Member, error)
+
+	CashRefreshToken(context.Context, users.ID, string, time.Duration) error
+	GetUserIDByRefreshToken(context.Context, string) (string, error)
+	DeleteSession(context.Context, string) error
+	CreateOneTimeToken(context.Context, tokens.OneTimeToken) error
+	GetOneTimeToken(context.Context, tokens.Purpose, string) (tokens.OneTimeToken, error)
+	ConsumeOneTimeToken(context.Context, tokens.Purpose, string) (tokens.OneTimeToken, error)
+	IncrRate(context.Context, string, users.Email, time.Duration) (int64, error)
 }
 
-func NewService(repository Repository, jwt *Manager, sender *mail.Sender) *Service {
+func NewService(
+	repository Repository,
+	jwt *Manager,
+	bus *events.Bus,
+	passwords *PasswordPolicy,
+	hasher users.Hasher,
+	registration *RegistrationPolicy,
+	store *config.Store,
+	registerer prometheus.Registerer,
+	logger *zap.Logger,
+) *Service {
 	return &Service{
-		repository: repository,
-		jwt:        jwt,
-		sender:     sender,
+		logger:       logger,
+		store:        store,
+		metrics:      newServiceMetrics(registerer),
+		repository:   repository,
+		jwt:          jwt,
+		bus:          bus,
+		passwords:    passwords,
+		hasher:       hasher,
+		registration: registration,
 	}
 }
 
 type Service struct {
-	repository Repository
-	jwt        *Manager
-	sender     *mail.Sender
+	logger       *zap.Logger
+	store        *config.Store
+	metrics      *serviceMetrics
+	repository   Repository
+	jwt          *Manager
+	bus          *events.Bus
+	passwords    *PasswordPolicy
+	hasher       users.Hasher
+	registration *RegistrationPolicy
 }
 
-func (s *Service) Register(ctx context.Context, password string, email users.Email) error {
-	if !users.ValidateEmail(email) || !validatePass(password) {
-		return InvalidData
+// log returns the logger of the request in ctx, which tags the lines with
+// its request ID, route and user.
+func (s *Service) log(ctx context.Context) *zap.Logger {
+	return logging.FromContext(ctx, s.logger)
+}
+
+// Register creates an account. With a valid invitation the account is
+// confirmed straight away, since the invitation itself was sent to the email.
+// legacy marks a password sent as the client-side pwd_hash of the v1 API,
+// see AuthV2.
+func (s *Service) Register(ctx context.Context, password string, legacy bool, email users.Email, inviteToken string) error {
+	if !users.ValidateEmail(email) {
+		return InvalidEmail
+	}
+	if err := s.passwords.Validate(password, string(email)); err != nil {
+		return err
 	}
 
 	if user, _ := s.repository.GetUserByEmail(ctx, email); user.Email == email {
 		return UserAlreadyExist
 	}
 
-	saltPass, err := salt(password)
+	invite, err := s.checkRegistration(ctx, email, inviteToken)
 	if err != nil {
 		return err
 	}
 
-	user := users.NewUser(users.Email(email), saltPass, "simple")
-	err = s.repository.CreateUser(ctx, user)
+	saltPass, err := s.hash(ctx, password)
 	if err != nil {
 		return err
 	}
 
-	refresh := s.jwt.CreateRefreshToken()
-	err = s.repository.CashRefreshToken(user.ID, refresh, RefreshKeyTime*time.Second)
+	user := users.NewUser(users.Email(email), saltPass, users.StatusSimple)
+	user.LegacyPassword = legacy
+	user.Confirmed = invite != nil
+	if invite != nil {
+		return s.createInvitedUser(ctx, user, *invite)
+	}
+
+	// The confirmation mail is recorded with the account, so it is sent
+	// exactly when the account really exists.
+	confirmation, err := s.confirmationEvent(ctx, user)
+	if err != nil {
+		return err
+	}
+	return s.repository.CreateUser(ctx, user, confirmation)
+}
+
+// ResendConfirmation mails a new confirmation link to an unconfirmed
+// account. Unknown and confirmed emails succeed without a mail, and the
+// rate limit applies to every email, so the endpoint cant be used to find
+// out which emails are registered.
+func (s *Service) ResendConfirmation(ctx context.Context, email users.Email) error {
+	if !users.ValidateEmail(email) {
+		return InvalidEmail
+	}
+	if err := s.checkRate(ctx, confirmRateAction, email, s.store.Current().RateLimits.ConfirmResend); err != nil {
+		return err
+	}
+
+	user, err := s.repository.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user.Email != email || user.Confirmed {
+		return nil
+	}
+
+	confirmation, err := s.confirmationEvent(ctx, user)
+	if err != nil {
+		return err
+	}
+	return s.bus.Publish(ctx, confirmation)
+}
+
+func (s *Service) confirmationEvent(ctx context.Context, user users.User) (events.Event, error) {
+	token, err := s.issueToken(ctx, tokens.Confirmation, user.ID, RefreshKeyTime*time.Second, nil)
+	if err != nil {
+		return events.Event{}, err
+	}
+
+	return mailEvent(events.ConfirmationRequested, user.ID, user.Email, user.Locale, token), nil
+}
+
+func (s *Service) publishMail(ctx context.Context, eventType events.Type, userID users.ID, email users.Email, locale, token string) error {
+	return s.bus.Publish(ctx, mailEvent(eventType, userID, email, locale, token))
+}
+
+func mailEvent(eventType events.Type, userID users.ID, email users.Email, locale, token string) events.Event {
+	return events.NewEvent(eventType, userID, nil, map[string]string{
+		events.PayloadEmail:  string(email),
+		events.PayloadLocale: locale,
+		events.PayloadToken:  token,
+	})
+}
+
+// checkRate reads the limit on every call, so reloaded limits apply at once.
+func (s *Service) checkRate(ctx context.Context, action string, email users.Email, limit config.RateLimit) error {
+	count, err := s.repository.IncrRate(ctx, action, email, limit.Window)
 	if err != nil {
 		return err
 	}
+	if count > limit.Limit {
+		s.metrics.rateLimited.WithLabelValues(action).Inc()
+		return TooManyRequests
+	}
+	return nil
+}
+
+func (s *Service) issueToken(ctx context.Context, purpose tokens.Purpose, userID users.ID, ttl time.Duration, payload map[string]string) (string, error) {
+	token := tokens.NewOneTimeToken(purpose, userID, ttl, payload)
+	if err := s.repository.CreateOneTimeToken(ctx, token); err != nil {
+		return "", err
+	}
+	return token.Token, nil
+}
 
-	err = s.sender.SendMail(string(email), fmt.Sprintf(confimEmailMsg, email, refresh))
+// peekToken looks the token up without redeeming it.
+func (s *Service) peekToken(ctx context.Context, purpose tokens.Purpose, token string) (tokens.OneTimeToken, error) {
+	result, err := s.repository.GetOneTimeToken(ctx, purpose, token)
+	if err != nil {
+		s.log(ctx).Info("Auth: Cant find one-time token", zap.String("purpose", string(purpose)), zap.Error(err))
+		return tokens.OneTimeToken{}, InvalidToken
+	}
+	return result, nil
+}
 
-	return err
+func (s *Service) consumeToken(ctx context.Context, purpose tokens.Purpose, token string) (tokens.OneTimeToken, error) {
+	result, err := s.repository.ConsumeOneTimeToken(ctx, purpose, token)
+	if err != nil {
+		s.log(ctx).Info("Auth: Cant consume one-time token", zap.String("purpose", string(purpose)), zap.Error(err))
+		return tokens.OneTimeToken{}, InvalidToken
+	}
+	return result, nil
 }
 
-func salt(pass string) (string, error) {
-	saltPass, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.MinCost)
-	return string(saltPass), err
+func (s *Service) DeleteUnconfirmedUsers(ctx context.Context, ttl time.Duration) (int64, error) {
+	return s.repository.DeleteUnconfirmedUsers(ctx, time.Now().Add(-ttl))
 }
 
 func (s *Service) Logout(ctx context.Context, refresh string) error {
-	return s.repository.DeleteSession(refresh)
+	return s.repository.DeleteSession(ctx, refresh)
+}
+
+// Auth signs in with the pwd_hash of the v1 API, which is checked as is.
+func (s *Service) Auth(ctx context.Context, pwdHash string, email users.Email) (users.Session, error) {
+	return s.observeSignIn(ctx, pwdHash, email, func(user users.User) bool {
+		if !user.CheckCerds(email, pwdHash) {
+			return false
+		}
+		s.rehashIfNeeded(ctx, user, pwdHash)
+		return true
+	})
+}
+
+// AuthV2 signs in with the plaintext password. Accounts whose password was
+// set through the v1 API store a hash of the client-side pwd_hash, which
+// cant be derived from the password here. Clients send that pwd_hash along
+// as legacyHash until it has matched once; the password is then hashed
+// again and the account is no longer legacy, so from then on only clients
+// sending the password itself can sign in.
+func (s *Service) AuthV2(ctx context.Context, password, legacyHash string, email users.Email) (users.Session, error) {
+	return s.observeSignIn(ctx, password, email, func(user users.User) bool {
+		switch {
+		case user.CheckCerds(email, password):
+			if user.LegacyPassword {
+				s.upgradePassword(ctx, user, password)
+			} else {
+				s.rehashIfNeeded(ctx, user, password)
+			}
+			return true
+		case user.LegacyPassword && legacyHash != "" && user.CheckCerds(email, legacyHash):
+			s.upgradePassword(ctx, user, password)
+			return true
+		}
+		return false
+	})
 }
 
-func (s *Service) Auth(ctx context.Context, password string, email users.Email) (users.Session, error) {
-	if !users.ValidateEmail(email) || !validatePass(password) {
+func (s *Service) observeSignIn(ctx context.Context, password string, email users.Email, verify func(users.User) bool) (users.Session, error) {
+	ctx, span := startSpan(ctx, "auth.SignIn")
+	session, err := s.signIn(ctx, password, email, verify)
+	endSpan(span, err)
+	s.metrics.observe(signInEvent, err)
+	return session, err
+}
+
+// signIn opens a session for the user with the email once verify accepts
+// the credentials.
+func (s *Service) signIn(ctx context.Context, password string, email users.Email, verify func(users.User) bool) (users.Session, error) {
+	if !users.ValidateEmail(email) {
+		return users.Session{}, InvalidEmail
+	}
+	if password == "" {
 		return users.Session{}, InvalidData
 	}
 
 	user, err := s.repository.GetUserByEmail(ctx, email)
 	if user.Email == "" || err != nil {
-		log.Printf("Auth: Cant get user: %v", err)
+		s.log(ctx).Info("Auth: Cant get user", zap.Error(err))
 		return users.Session{}, IncorrectCreds
 	}
 
@@ -116,44 +311,101 @@ func (s *Service) Auth(ctx context.Context, password string, email users.Email)
 		return users.Session{}, NonConfirmed
 	}
 
-	credsCorrect := user.CheckCerds(email, password)
+	_, span := startSpan(ctx, "password.verify")
+	credsCorrect := verify(user)
+	span.End()
 
 	if !credsCorrect {
 		return users.Session{}, IncorrectCreds
 	}
 
+	if err = s.repository.UpdateLastLogin(ctx, user.ID, time.Now()); err != nil {
+		s.log(ctx).Warn("Auth: Cant update last login", zap.Uint64("user_id", uint64(user.ID)), zap.Error(err))
+	}
+
 	token, err := s.jwt.CreateToken(user.ID)
 	if err != nil {
-		log.Printf("Auth: Cant CreateToken: %v", err)
+		s.log(ctx).Error("Auth: Cant create token", zap.Uint64("user_id", uint64(user.ID)), zap.Error(err))
 	}
 
 	refresh := s.jwt.CreateRefreshToken()
 
-	session := users.Session{token, refresh}
-	err = s.repository.CashRefreshToken(user.ID, refresh, SessionTime*time.Second)
+	session := users.Session{Token: token, Refresh: refresh}
+	err = s.repository.CashRefreshToken(ctx, user.ID, refresh, SessionTime*time.Second)
 	if err != nil {
-		log.Printf("Auth: Cant Add Session: %v", err)
+		s.log(ctx).Error("Auth: Cant add session", zap.Uint64("user_id", uint64(user.ID)), zap.Error(err))
 	}
 
 	return session, err
 }
 
-func (s *Service) ChangePassword(ctx context.Context, restoreRefresh, newPassword string) (users.Session, error) {
-	if !validatePass(newPassword) {
-		return users.Session{}, InvalidData
+// rehashIfNeeded upgrades a hash made with an outdated algorithm or cost
+// while the plaintext password is at hand. Failures only cost the upgrade.
+func (s *Service) rehashIfNeeded(ctx context.Context, user users.User, password string) {
+	if !s.hasher.NeedsRehash(user.PwdHash) {
+		return
 	}
 
-	userID, err := s.repository.GetUserIDByRestoreRefresh(restoreRefresh)
+	newHash, err := s.hash(ctx, password)
 	if err != nil {
-		return users.Session{}, InvalidRefresh
+		s.log(ctx).Warn("Auth: Cant rehash password", zap.Uint64("user_id", uint64(user.ID)), zap.Error(err))
+		return
+	}
+
+	if err = s.repository.ChangePasswordByUserID(ctx, user.ID, newHash, user.LegacyPassword); err != nil {
+		s.log(ctx).Warn("Auth: Cant save rehashed password", zap.Uint64("user_id", uint64(user.ID)), zap.Error(err))
 	}
+}
+
+// upgradePassword replaces the hash of a legacy pwd_hash with a hash of the
+// plaintext password. Nothing ties that password to the pwd_hash it comes
+// with, so it must pass the password policy like any new password; if it
+// doesnt, the account stays legacy. Failures only postpone the upgrade.
+func (s *Service) upgradePassword(ctx context.Context, user users.User, password string) {
+	if err := s.passwords.Validate(password, string(user.Email)); err != nil {
+		s.log(ctx).Info("Auth: Kept legacy password rejected by the password policy", zap.Uint64("user_id", uint64(user.ID)), zap.Error(err))
+		return
+	}
+
+	newHash, err := s.hash(ctx, password)
+	if err != nil {
+		s.log(ctx).Warn("Auth: Cant hash legacy password", zap.Uint64("user_id", uint64(user.ID)), zap.Error(err))
+		return
+	}
+
+	if err = s.repository.ChangePasswordByUserID(ctx, user.ID, newHash, false); err != nil {
+		s.log(ctx).Warn("Auth: Cant save upgraded password", zap.Uint64("user_id", uint64(user.ID)), zap.Error(err))
+		return
+	}
+	s.log(ctx).Info("Auth: Upgraded legacy password", zap.Uint64("user_id", uint64(user.ID)))
+}
+
+// ChangePassword sets the password of the user the restore token was
+// issued to. The token is only spent once the new password is accepted, so
+// a rejected password can be corrected. legacy is as for Register.
+func (s *Service) ChangePassword(ctx context.Context, restoreRefresh, newPassword string, legacy bool) (users.Session, error) {
+	restore, err := s.peekToken(ctx, tokens.RestorePassword, restoreRefresh)
+	if err != nil {
+		return users.Session{}, err
+	}
+	userID := restore.UserID
 
 	user, err := s.repository.GetUserByUserID(ctx, userID)
+	if err != nil {
+		return users.Session{}, err
+	}
+	if err := s.passwords.Validate(newPassword, string(user.Email)); err != nil {
+		return users.Session{}, err
+	}
 	if users.DoPasswordsMatch(user.PwdHash, newPassword) {
 		return users.Session{}, SamePassword
 	}
 
-	saltPass, err := salt(newPassword)
+	if _, err := s.consumeToken(ctx, tokens.RestorePassword, restoreRefresh); err != nil {
+		return users.Session{}, err
+	}
+
+	saltPass, err := s.hash(ctx, newPassword)
 	if err != nil {
 		return users.Session{}, err
 	}
@@ -161,23 +413,17 @@ func (s *Service) ChangePassword(ctx context.Context, restoreRefresh, newPasswor
 	token, _ := s.jwt.CreateToken(userID)
 	refresh := s.jwt.CreateRefreshToken()
 
-	return users.Session{Token: token, Refresh: refresh}, s.repository.ChangePasswordByUserID(ctx, userID, saltPass)
+	return users.Session{Token: token, Refresh: refresh}, s.repository.ChangePasswordByUserID(ctx, userID, saltPass, legacy)
 }
 
-func validatePass(pass string) bool {
-	if lenPass := len(pass); lenPass < 6 || lenPass > 20 {
-		return false
-	}
-	if check, _ := regexp.MatchString("^[\\x20-\\x7E]+$", pass); !check {
-		return false
-	}
-	if check, _ := regexp.MatchString("[0-9]", pass); !check {
-		return false
-	}
-	if check, _ := regexp.MatchString("[A-Za-z]", pass); !check {
-		return false
+// IsAdmin reports whether the user has the admin status that guards
+// operational endpoints.
+func (s *Service) IsAdmin(ctx context.Context, userID users.ID) (bool, error) {
+	user, err := s.repository.GetUserByUserID(ctx, userID)
+	if err != nil {
+		return false, err
 	}
-	return true
+	return user.ID == userID && user.Status == users.StatusAdmin, nil
 }
 
 func (s *Service) UnmarshalToken(token string) (users.ID, error) {
@@ -186,62 +432,74 @@ func (s *Service) UnmarshalToken(token string) (users.ID, error) {
 }
 
 func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (users.Session, error) {
-	userID, err := s.repository.GetUserIDByRefreshToken(refreshToken)
+	ctx, span := startSpan(ctx, "auth.Refresh")
+	session, err := s.refresh(ctx, refreshToken)
+	endSpan(span, err)
+	s.metrics.observe(refreshEvent, err)
+	return session, err
+}
+
+func (s *Service) refresh(ctx context.Context, refreshToken string) (users.Session, error) {
+	userID, err := s.repository.GetUserIDByRefreshToken(ctx, refreshToken)
 	if err != nil {
-		log.Printf("RefreshToken: Cant Get Session: %v", err)
+		s.log(ctx).Error("Auth: Cant get session", zap.Error(err))
 		return users.Session{}, err
 	}
 
-	uid, _ := strconv.Atoi(userID)
+	// Unknown and expired tokens have no user.
+	uid, err := strconv.Atoi(userID)
+	if err != nil {
+		return users.Session{}, InvalidRefresh
+	}
 	token, err := s.jwt.CreateToken(users.ID(uid))
 	if err != nil {
-		log.Printf("Auth: Cant CreateToken: %v", err)
+		s.log(ctx).Error("Auth: Cant create token", zap.Int("user_id", uid), zap.Error(err))
 		return users.Session{}, err
 	}
 
 	newRefreshToken := s.jwt.CreateRefreshToken()
-	if err = s.repository.DeleteSession(refreshToken); err != nil {
+	if err = s.repository.DeleteSession(ctx, refreshToken); err != nil {
 		return users.Session{}, err
 	}
-	err = s.repository.CashRefreshToken(users.ID(uid), newRefreshToken, SessionTime*time.Second)
+	err = s.repository.CashRefreshToken(ctx, users.ID(uid), newRefreshToken, SessionTime*time.Second)
 	if err != nil {
-		log.Printf("Auth: Cant Add Session: %v", err)
+		s.log(ctx).Error("Auth: Cant add session", zap.Int("user_id", uid), zap.Error(err))
 		return users.Session{}, err
 	}
 
 	return users.Session{Token: token, Refresh: newRefreshToken}, nil
 }
 
-func (s *Service) ConfirmationUser(ctx context.Context, restoreRefresh string) (users.Session, error) {
-	strUserID, err := s.repository.GetUserIDByRefreshToken(restoreRefresh)
+func (s *Service) ConfirmationUser(ctx context.Context, confirmToken string) (users.Session, error) {
+	confirmation, err := s.consumeToken(ctx, tokens.Confirmation, confirmToken)
 	if err != nil {
-		return users.Session{}, InvalidRefresh
+		return users.Session{}, err
 	}
-	s.repository.DeleteSession(restoreRefresh)
-	userID, _ := strconv.Atoi(strUserID)
+	userID := confirmation.UserID
 
-	token, _ := s.jwt.CreateToken(users.ID(userID))
+	token, _ := s.jwt.CreateToken(userID)
 	refresh := s.jwt.CreateRefreshToken()
 
-	return users.Session{Token: token, Refresh: refresh}, s.repository.ConfirmUser(ctx, users.ID(userID))
+	return users.Session{Token: token, Refresh: refresh}, s.repository.ConfirmUser(ctx, userID)
 }
 
 func (s *Service) SendRestorePasswordMail(ctx context.Context, email users.Email) error {
 	if !users.ValidateEmail(email) {
-		return InvalidData
+		return InvalidEmail
 	}
 	user, err := s.repository.GetUserByEmail(ctx, email)
-	if err != nil {
+	if err != nil || user.Email != email {
 		return NotFoundEmail
 	}
 
-	refresh := s.jwt.CreateRefreshToken()
-	err = s.repository.CreateRestoreRefresh(email, user.ID, refresh)
-	if err != nil {
+	if err = s.checkRate(ctx, restoreRateAction, email, s.store.Current().RateLimits.Restore); err != nil {
 		return err
 	}
 
-	s.sender.SendMail(string(email), fmt.Sprintf(changePassMsg, string(email), refresh))
+	refresh, err := s.issueToken(ctx, tokens.RestorePassword, user.ID, RestoreKeyTime*time.Second, nil)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return s.publishMail(ctx, events.PasswordRestoreRequested, user.ID, email, user.Locale, refresh)
 }
@@ -0,0 +1,433 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/invites"
+	"task-manager-backend/internal/app/models/tokens"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/models/workspaces"
+	"testing"
+	"time"
+)
+
+// memoryRepository keeps users, invitations, memberships and one-time
+// tokens in maps. Methods the tests dont reach are left to the embedded nil
+// interface.
+type memoryRepository struct {
+	Repository
+	users   map[users.Email]users.User
+	tokens  map[string]tokens.OneTimeToken
+	invites map[string]invites.Invite
+	members []workspaces.Member
+	rates   map[string]int64
+	// deletedBefore is the cutoff DeleteUnconfirmedUsers was called with.
+	deletedBefore time.Time
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{
+		users:   make(map[users.Email]users.User),
+		tokens:  make(map[string]tokens.OneTimeToken),
+		invites: make(map[string]invites.Invite),
+		rates:   make(map[string]int64),
+	}
+}
+
+func (r *memoryRepository) CreateUser(_ context.Context, user users.User, _ ...events.Event) error {
+	if _, ok := r.users[user.Email]; ok {
+		return errors.New("duplicate email")
+	}
+	r.users[user.Email] = user
+	return nil
+}
+
+func (r *memoryRepository) GetUserByEmail(_ context.Context, email users.Email) (users.User, error) {
+	return r.users[email], nil
+}
+
+func (r *memoryRepository) GetUserByUserID(_ context.Context, userID users.ID) (users.User, error) {
+	for _, user := range r.users {
+		if user.ID == userID {
+			return user, nil
+		}
+	}
+	return users.User{}, nil
+}
+
+func (r *memoryRepository) ChangePasswordByUserID(_ context.Context, userID users.ID, hash string, legacy bool) error {
+	for email, user := range r.users {
+		if user.ID == userID {
+			user.PwdHash = hash
+			user.LegacyPassword = legacy
+			r.users[email] = user
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) CreateInvite(_ context.Context, invite invites.Invite) error {
+	r.invites[invite.Token] = invite
+	return nil
+}
+
+func (r *memoryRepository) GetInvite(_ context.Context, token string) (invites.Invite, error) {
+	return r.invites[token], nil
+}
+
+func (r *memoryRepository) CreateInvitedUser(ctx context.Context, user users.User, invite invites.Invite, announce ...events.Event) error {
+	stored := r.invites[invite.Token]
+	if !stored.Pending() {
+		return invites.ErrNotPending
+	}
+	if err := r.CreateUser(ctx, user, announce...); err != nil {
+		return err
+	}
+	if invite.WorkspaceID != nil {
+		r.members = append(r.members, workspaces.Member{WorkspaceID: *invite.WorkspaceID, UserID: user.ID, Role: invite.Role})
+	}
+	now := time.Now()
+	stored.AcceptedAt = &now
+	r.invites[invite.Token] = stored
+	return nil
+}
+
+func (r *memoryRepository) GetWorkspaceMember(_ context.Context, workspaceID workspaces.ID, userID users.ID) (workspaces.Member, error) {
+	for _, member := range r.members {
+		if member.WorkspaceID == workspaceID && member.UserID == userID {
+			return member, nil
+		}
+	}
+	return workspaces.Member{}, nil
+}
+
+func (r *memoryRepository) UpdateLastLogin(context.Context, users.ID, time.Time) error {
+	return nil
+}
+
+func (r *memoryRepository) CashRefreshToken(context.Context, users.ID, string, time.Duration) error {
+	return nil
+}
+
+func (r *memoryRepository) CreateOneTimeToken(_ context.Context, token tokens.OneTimeToken) error {
+	r.tokens[string(token.Purpose)+token.Token] = token
+	return nil
+}
+
+func (r *memoryRepository) GetOneTimeToken(_ context.Context, purpose tokens.Purpose, token string) (tokens.OneTimeToken, error) {
+	result, ok := r.tokens[string(purpose)+token]
+	if !ok {
+		return tokens.OneTimeToken{}, errors.New("token not found")
+	}
+	return result, nil
+}
+
+func (r *memoryRepository) ConsumeOneTimeToken(ctx context.Context, purpose tokens.Purpose, token string) (tokens.OneTimeToken, error) {
+	result, err := r.GetOneTimeToken(ctx, purpose, token)
+	delete(r.tokens, string(purpose)+token)
+	return result, err
+}
+
+func (r *memoryRepository) IncrRate(_ context.Context, action string, email users.Email, _ time.Duration) (int64, error) {
+	r.rates[action+"/"+string(email)]++
+	return r.rates[action+"/"+string(email)], nil
+}
+
+func (r *memoryRepository) DeleteUnconfirmedUsers(_ context.Context, registeredBefore time.Time) (int64, error) {
+	r.deletedBefore = registeredBefore
+	var deleted int64
+	for email, user := range r.users {
+		if !user.Confirmed && user.RegDate.Before(registeredBefore) {
+			delete(r.users, email)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func newTestService(repository Repository) *Service {
+	return &Service{
+		logger:       zap.NewNop(),
+		store:        config.NewStore(config.Default(), ""),
+		metrics:      newServiceMetrics(prometheus.NewRegistry()),
+		repository:   repository,
+		jwt:          &Manager{Secret: "test", Expiration: time.Minute},
+		bus:          events.NewBus(),
+		passwords:    NewPasswordPolicy(config.Default()),
+		hasher:       users.BcryptHasher{Cost: bcrypt.MinCost},
+		registration: &RegistrationPolicy{mode: RegistrationOpen},
+	}
+}
+
+// registerConfirmed signs the user up and confirms the account, as the
+// confirmation link would.
+func registerConfirmed(t *testing.T, s *Service, repository *memoryRepository, password string, legacy bool, email users.Email) {
+	t.Helper()
+	if err := s.Register(context.Background(), password, legacy, email, ""); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	user := repository.users[email]
+	user.Confirmed = true
+	repository.users[email] = user
+}
+
+func TestAuthV2UpgradesV1Account(t *testing.T) {
+	const (
+		email    = users.Email("legacy@example.com")
+		password = "plain1password"
+		pwdHash  = "client1hash"
+	)
+	ctx := context.Background()
+	repository := newMemoryRepository()
+	s := newTestService(repository)
+	registerConfirmed(t, s, repository, pwdHash, true, email)
+
+	if !repository.users[email].LegacyPassword {
+		t.Fatal("account registered through v1 is not marked legacy")
+	}
+	if _, err := s.Auth(ctx, pwdHash, email); err != nil {
+		t.Fatalf("v1 sign-in of a legacy account: %v", err)
+	}
+	if _, err := s.AuthV2(ctx, password, "", email); err != IncorrectCreds {
+		t.Fatalf("v2 sign-in of a legacy account without pwd_hash: got %v, want %v", err, IncorrectCreds)
+	}
+	if _, err := s.AuthV2(ctx, password, "wrong1hash", email); err != IncorrectCreds {
+		t.Fatalf("v2 sign-in of a legacy account with a wrong pwd_hash: got %v, want %v", err, IncorrectCreds)
+	}
+
+	if _, err := s.AuthV2(ctx, password, pwdHash, email); err != nil {
+		t.Fatalf("v2 sign-in of a legacy account with its pwd_hash: %v", err)
+	}
+	if repository.users[email].LegacyPassword {
+		t.Fatal("account is still legacy after signing in through v2")
+	}
+	if _, err := s.AuthV2(ctx, password, "", email); err != nil {
+		t.Fatalf("v2 sign-in of an upgraded account: %v", err)
+	}
+}
+
+// The password sent along with a legacy pwd_hash cant be checked against
+// it, so a password the policy rejects must not replace it.
+func TestAuthV2KeepsLegacyPasswordRejectedByPolicy(t *testing.T) {
+	const (
+		email   = users.Email("weak@example.com")
+		pwdHash = "client1hash"
+	)
+	ctx := context.Background()
+	repository := newMemoryRepository()
+	s := newTestService(repository)
+	registerConfirmed(t, s, repository, pwdHash, true, email)
+	stored := repository.users[email].PwdHash
+
+	if _, err := s.AuthV2(ctx, "a", pwdHash, email); err != nil {
+		t.Fatalf("v2 sign-in with the pwd_hash: %v", err)
+	}
+	if user := repository.users[email]; !user.LegacyPassword || user.PwdHash != stored {
+		t.Fatal("password rejected by the policy replaced the legacy one")
+	}
+	if _, err := s.AuthV2(ctx, "a", "", email); err != IncorrectCreds {
+		t.Fatalf("sign-in with the rejected password alone: got %v, want %v", err, IncorrectCreds)
+	}
+}
+
+// Clients that sent the password itself as pwd_hash need no pwd_hash in v2.
+func TestAuthV2UpgradesV1AccountWithPlainPwdHash(t *testing.T) {
+	const (
+		email    = users.Email("plain@example.com")
+		password = "plain1password"
+	)
+	ctx := context.Background()
+	repository := newMemoryRepository()
+	s := newTestService(repository)
+	registerConfirmed(t, s, repository, password, true, email)
+
+	if _, err := s.AuthV2(ctx, password, "", email); err != nil {
+		t.Fatalf("v2 sign-in: %v", err)
+	}
+	if repository.users[email].LegacyPassword {
+		t.Fatal("account is still legacy after signing in through v2")
+	}
+}
+
+func TestAuthV2IgnoresPwdHashOfV2Account(t *testing.T) {
+	const (
+		email    = users.Email("modern@example.com")
+		password = "plain1password"
+	)
+	ctx := context.Background()
+	repository := newMemoryRepository()
+	s := newTestService(repository)
+	registerConfirmed(t, s, repository, password, false, email)
+
+	// The pwd_hash path is only open to legacy accounts.
+	if _, err := s.AuthV2(ctx, "wrong1password", password, email); err != IncorrectCreds {
+		t.Fatalf("got %v, want %v", err, IncorrectCreds)
+	}
+}
+
+func TestRegisterWithInvite(t *testing.T) {
+	const (
+		email       = users.Email("invitee@example.com")
+		password    = "plain1password"
+		workspaceID = workspaces.ID(7)
+	)
+	ctx := context.Background()
+	repository := newMemoryRepository()
+	s := newTestService(repository)
+	s.registration = &RegistrationPolicy{mode: RegistrationInvite}
+
+	owner := users.NewUser("owner@example.com", "", users.StatusSimple)
+	repository.users[owner.Email] = owner
+	repository.members = append(repository.members, workspaces.Member{WorkspaceID: workspaceID, UserID: owner.ID, Role: workspaces.RoleOwner})
+
+	var token string
+	s.bus.Subscribe(events.UserInvited, func(_ context.Context, event events.Event) error {
+		token = event.Payload[events.PayloadToken]
+		return nil
+	})
+	if err := s.CreateInvite(ctx, owner.ID, email, workspaceID, ""); err != nil {
+		t.Fatalf("CreateInvite: %v", err)
+	}
+
+	// Someone holding the token cant spend it on another email.
+	if err := s.Register(ctx, password, false, "intruder@example.com", token); err != InvalidInvite {
+		t.Fatalf("Register with another email: got %v, want %v", err, InvalidInvite)
+	}
+	if invite := repository.invites[token]; !invite.Pending() {
+		t.Fatal("invitation was spent by a registration with another email")
+	}
+
+	if err := s.Register(ctx, password, false, email, token); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	user := repository.users[email]
+	if !user.Confirmed {
+		t.Error("invited user is not confirmed")
+	}
+	if invite := repository.invites[token]; invite.Pending() {
+		t.Error("invitation is still pending")
+	}
+	if member, _ := repository.GetWorkspaceMember(ctx, workspaceID, user.ID); member.Role != workspaces.RoleMember {
+		t.Errorf("invited user joined the workspace as %q, want %q", member.Role, workspaces.RoleMember)
+	}
+
+	if err := s.Register(ctx, password, false, email, token); err == nil {
+		t.Error("invitation was accepted twice")
+	}
+}
+
+func TestCreateInvite(t *testing.T) {
+	const workspaceID = workspaces.ID(7)
+	ctx := context.Background()
+	repository := newMemoryRepository()
+	s := newTestService(repository)
+
+	admin := users.NewUser("admin@example.com", "", users.StatusAdmin)
+	member := users.NewUser("member@example.com", "", users.StatusSimple)
+	repository.users[admin.Email] = admin
+	repository.users[member.Email] = member
+	repository.members = append(repository.members, workspaces.Member{WorkspaceID: workspaceID, UserID: member.ID, Role: workspaces.RoleMember})
+
+	for _, tt := range []struct {
+		name        string
+		inviter     users.ID
+		workspaceID workspaces.ID
+		role        workspaces.Role
+		want        error
+	}{
+		{"admin without workspace", admin.ID, 0, "", nil},
+		{"member without workspace", member.ID, 0, "", NotAllowed},
+		{"plain member of the workspace", member.ID, workspaceID, "", NotAllowed},
+		{"admin outside the workspace", admin.ID, workspaceID, "", NotAllowed},
+		{"role without workspace", admin.ID, 0, workspaces.RoleMember, InvalidData},
+		{"owner role", admin.ID, workspaceID, workspaces.RoleOwner, InvalidData},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.CreateInvite(ctx, tt.inviter, "new@example.com", tt.workspaceID, tt.role); err != tt.want {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResendConfirmation(t *testing.T) {
+	ctx := context.Background()
+	repository := newMemoryRepository()
+	s := newTestService(repository)
+
+	unconfirmed := users.NewUser("unconfirmed@example.com", "", users.StatusSimple)
+	confirmed := users.NewUser("confirmed@example.com", "", users.StatusSimple)
+	confirmed.Confirmed = true
+	repository.users[unconfirmed.Email] = unconfirmed
+	repository.users[confirmed.Email] = confirmed
+
+	mailed := make(map[string]int)
+	s.bus.Subscribe(events.ConfirmationRequested, func(_ context.Context, event events.Event) error {
+		mailed[event.Payload[events.PayloadEmail]]++
+		return nil
+	})
+
+	limit := config.Default().RateLimits.ConfirmResend.Limit
+	for _, email := range []users.Email{unconfirmed.Email, confirmed.Email, "unknown@example.com"} {
+		// Every email gets the same answers, registered or not.
+		for i := int64(0); i < limit; i++ {
+			if err := s.ResendConfirmation(ctx, email); err != nil {
+				t.Fatalf("resend to %s: %v", email, err)
+			}
+		}
+		if err := s.ResendConfirmation(ctx, email); err != TooManyRequests {
+			t.Fatalf("resend to %s over the limit: got %v, want %v", email, err, TooManyRequests)
+		}
+	}
+
+	if mailed[string(unconfirmed.Email)] != int(limit) {
+		t.Errorf("unconfirmed account got %d mails, want %d", mailed[string(unconfirmed.Email)], limit)
+	}
+	if len(mailed) != 1 {
+		t.Errorf("mails went to %v, want only the unconfirmed account", mailed)
+	}
+	if err := s.ResendConfirmation(ctx, "not an email"); err != InvalidEmail {
+		t.Errorf("invalid email: got %v, want %v", err, InvalidEmail)
+	}
+}
+
+func TestDeleteUnconfirmedUsers(t *testing.T) {
+	ctx := context.Background()
+	repository := newMemoryRepository()
+	s := newTestService(repository)
+
+	stale := users.NewUser("stale@example.com", "", users.StatusSimple)
+	stale.RegDate = time.Now().Add(-2 * time.Hour)
+	recent := users.NewUser("recent@example.com", "", users.StatusSimple)
+	confirmed := users.NewUser("confirmed@example.com", "", users.StatusSimple)
+	confirmed.RegDate = stale.RegDate
+	confirmed.Confirmed = true
+	for _, user := range []users.User{stale, recent, confirmed} {
+		repository.users[user.Email] = user
+	}
+
+	deleted, err := s.DeleteUnconfirmedUsers(ctx, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cutoff := time.Since(repository.deletedBefore); cutoff < time.Hour || cutoff > time.Hour+time.Minute {
+		t.Fatalf("deleted users registered %s ago, want an hour", cutoff)
+	}
+	if deleted != 1 {
+		t.Fatalf("deleted %d users, want 1", deleted)
+	}
+	if _, ok := repository.users[stale.Email]; ok {
+		t.Error("stale unconfirmed user was kept")
+	}
+	if _, ok := repository.users[recent.Email]; !ok {
+		t.Error("recent unconfirmed user was deleted")
+	}
+	if _, ok := repository.users[confirmed.Email]; !ok {
+		t.Error("confirmed user was deleted")
+	}
+}
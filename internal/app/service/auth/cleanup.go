@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/models/jobs"
+	"task-manager-backend/internal/app/queue"
+	"time"
+)
+
+const (
+	cleanupInterval           = time.Hour
+	CleanupJob      jobs.Kind = "auth.cleanup_unconfirmed"
+	cleanupJobKey             = string(CleanupJob)
+)
+
+// CleanupHook periodically removes accounts that were never confirmed
+// within the configured auth.unconfirmed_ttl. A zero TTL disables cleanup.
+// The run is a keyed job on the queue, so only one instance does it at a
+// time and each run schedules the next one. A failed cleanup is not retried
+// on its own; the next run catches up.
+func CleanupHook(lifecycle fx.Lifecycle, cfg config.ServiceConfiguration, service *Service, q *queue.Queue) {
+	ttl := cfg.Api.Auth.UnconfirmedTTL
+	if ttl <= 0 {
+		return
+	}
+
+	q.Register(CleanupJob, func(ctx context.Context, payload json.RawMessage) error {
+		deleted, err := service.DeleteUnconfirmedUsers(ctx, ttl)
+		if err != nil {
+			service.log(ctx).Error("Cleanup: Cant delete unconfirmed users", zap.Error(err))
+		} else if deleted > 0 {
+			service.log(ctx).Info("Cleanup: Deleted unconfirmed users", zap.Int64("deleted", deleted))
+		}
+
+		_, err = q.Schedule(ctx, CleanupJob, cleanupJobKey, nil, time.Now().Add(cleanupInterval))
+		return err
+	})
+
+	lifecycle.Append(
+		fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				_, err := q.Schedule(ctx, CleanupJob, cleanupJobKey, nil, time.Now())
+				return err
+			},
+		})
+}
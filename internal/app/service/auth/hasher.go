@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/models/users"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+const (
+	defaultBcryptCost        = 12
+	defaultArgon2Memory      = 64 * 1024
+	defaultArgon2Iterations  = 3
+	defaultArgon2Parallelism = 2
+)
+
+// NewHasher builds the hasher for new and upgraded passwords. Hashes made by
+// any supported algorithm keep verifying, see users.DoPasswordsMatch.
+func NewHasher(cfg config.ServiceConfiguration) (users.Hasher, error) {
+	hashCfg := cfg.Api.Auth.PasswordHash
+
+	switch hashCfg.Algorithm {
+	case AlgorithmBcrypt:
+		cost := hashCfg.BcryptCost
+		if cost == 0 {
+			cost = defaultBcryptCost
+		}
+		if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("NewHasher: bcrypt cost %d out of range", cost)
+		}
+		return users.BcryptHasher{Cost: cost}, nil
+	case AlgorithmArgon2id, "":
+		hasher := users.Argon2idHasher{
+			Memory:      hashCfg.Argon2Memory,
+			Iterations:  hashCfg.Argon2Iterations,
+			Parallelism: hashCfg.Argon2Parallelism,
+		}
+		if hasher.Memory == 0 {
+			hasher.Memory = defaultArgon2Memory
+		}
+		if hasher.Iterations == 0 {
+			hasher.Iterations = defaultArgon2Iterations
+		}
+		if hasher.Parallelism == 0 {
+			hasher.Parallelism = defaultArgon2Parallelism
+		}
+		return hasher, nil
+	default:
+		return nil, fmt.Errorf("NewHasher: unknown password hash algorithm %q", hashCfg.Algorithm)
+	}
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"reflect"
+	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/models/users"
+	"testing"
+)
+
+func TestNewHasher(t *testing.T) {
+	defaultArgon2id := users.Argon2idHasher{
+		Memory:      defaultArgon2Memory,
+		Iterations:  defaultArgon2Iterations,
+		Parallelism: defaultArgon2Parallelism,
+	}
+	for _, tt := range []struct {
+		name    string
+		hash    config.PasswordHash
+		want    users.Hasher
+		wantErr bool
+	}{
+		{"default", config.PasswordHash{}, defaultArgon2id, false},
+		{"argon2id defaults per setting", config.PasswordHash{Algorithm: AlgorithmArgon2id, Argon2Iterations: 5},
+			users.Argon2idHasher{Memory: defaultArgon2Memory, Iterations: 5, Parallelism: defaultArgon2Parallelism}, false},
+		{"bcrypt default cost", config.PasswordHash{Algorithm: AlgorithmBcrypt}, users.BcryptHasher{Cost: defaultBcryptCost}, false},
+		{"bcrypt cost", config.PasswordHash{Algorithm: AlgorithmBcrypt, BcryptCost: 10}, users.BcryptHasher{Cost: 10}, false},
+		{"bcrypt cost too low", config.PasswordHash{Algorithm: AlgorithmBcrypt, BcryptCost: 3}, nil, true},
+		{"bcrypt cost too high", config.PasswordHash{Algorithm: AlgorithmBcrypt, BcryptCost: 32}, nil, true},
+		{"unknown algorithm", config.PasswordHash{Algorithm: "md5"}, nil, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Api.Auth.PasswordHash = tt.hash
+
+			hasher, err := NewHasher(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(hasher, tt.want) {
+				t.Fatalf("got %#v, want %#v", hasher, tt.want)
+			}
+		})
+	}
+}
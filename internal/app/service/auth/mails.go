@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"context"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/service/mail"
+)
+
+const mailConsumer = "auth.mail"
+
+// RegisterMailHandlers queues the mails requested by auth events.
+func RegisterMailHandlers(bus *events.Bus, mailer *mail.Mailer, outbox events.OutboxRepository) {
+	bus.Subscribe(events.ConfirmationRequested, events.Once(mailConsumer, outbox, mailHandler(mailer, mail.ConfirmEmail)))
+	bus.Subscribe(events.PasswordRestoreRequested, events.Once(mailConsumer, outbox, mailHandler(mailer, mail.RestorePassword)))
+	bus.Subscribe(events.UserInvited, events.Once(mailConsumer, outbox, mailHandler(mailer, mail.Invite)))
+}
+
+func mailHandler(mailer *mail.Mailer, template string) events.Handler {
+	return func(ctx context.Context, event events.Event) error {
+		return mailer.Send(ctx, template, event.Payload[events.PayloadLocale], event.Payload[events.PayloadEmail], map[string]string{
+			"Token": event.Payload[events.PayloadToken],
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"task-manager-backend/internal/app/metrics"
+)
+
+// Events counted by auth_events_total.
+const (
+	signInEvent  = "sign_in"
+	refreshEvent = "refresh"
+)
+
+// Outcomes of an auth event.
+const (
+	outcomeSuccess     = "success"
+	outcomeFailure     = "failure"
+	outcomeUnconfirmed = "unconfirmed"
+	outcomeInvalid     = "invalid"
+	outcomeError       = "error"
+)
+
+type serviceMetrics struct {
+	events      *prometheus.CounterVec
+	rateLimited *prometheus.CounterVec
+}
+
+func newServiceMetrics(registerer prometheus.Registerer) *serviceMetrics {
+	factory := promauto.With(registerer)
+	return &serviceMetrics{
+		events: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "auth",
+			Name:      "events_total",
+			Help:      "Sign-ins and token refreshes by outcome.",
+		}, []string{"event", "outcome"}),
+		rateLimited: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "auth",
+			Name:      "rate_limited_total",
+			Help:      "Requests rejected by a per-email rate limit.",
+		}, []string{"action"}),
+	}
+}
+
+func (m *serviceMetrics) observe(event string, err error) {
+	m.events.WithLabelValues(event, outcome(err)).Inc()
+}
+
+func outcome(err error) string {
+	switch err {
+	case nil:
+		return outcomeSuccess
+	case IncorrectCreds, InvalidRefresh:
+		return outcomeFailure
+	case NonConfirmed:
+		return outcomeUnconfirmed
+	case InvalidData, InvalidEmail:
+		return outcomeInvalid
+	default:
+		return outcomeError
+	}
+}
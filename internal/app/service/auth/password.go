@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/nbutton23/zxcvbn-go"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"task-manager-backend/internal/app/config"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultMinPasswordLength = 6
+	defaultMaxPasswordLength = 20
+	breachedPrefixLength     = 5
+	// minInputWordLength leaves out parts of user inputs too short to make
+	// a password guessable, such as "com".
+	minInputWordLength = 4
+)
+
+// PasswordError is a password the policy rejects. The API reports it as a
+// problem with the request field carrying the password.
+type PasswordError struct {
+	code    string
+	message string
+	params  map[string]string
+}
+
+func (e *PasswordError) Error() string {
+	return e.message
+}
+
+func (e *PasswordError) Code() string {
+	return e.code
+}
+
+func (e *PasswordError) Params() map[string]string {
+	return e.params
+}
+
+var (
+	ErrPasswordNoDigit    = &PasswordError{code: "password_no_digit", message: "Password must contain a digit"}
+	ErrPasswordNoLetter   = &PasswordError{code: "password_no_letter", message: "Password must contain a letter"}
+	ErrPasswordNoUpper    = &PasswordError{code: "password_no_upper", message: "Password must contain an uppercase letter"}
+	ErrPasswordNoSymbol   = &PasswordError{code: "password_no_symbol", message: "Password must contain a special character"}
+	ErrPasswordBadSymbols = &PasswordError{code: "password_bad_symbols", message: "Password contains unsupported characters"}
+	ErrPasswordTooWeak    = &PasswordError{code: "password_too_weak", message: "Password is too easy to guess"}
+	ErrPasswordBreached   = &PasswordError{code: "password_breached", message: "Password has appeared in a data breach"}
+)
+
+type PasswordPolicy struct {
+	minLength      int
+	maxLength      int
+	requireDigit   bool
+	requireLetter  bool
+	requireUpper   bool
+	requireSymbol  bool
+	allowUnicode   bool
+	minStrength    int
+	breachedCorpus string
+}
+
+// NewPasswordPolicy applies the configured rules. Their defaults are the
+// historical ones (6-20 printable ASCII characters with a digit and a
+// letter) and are set per setting by config.Default, so configuring one
+// rule keeps the others.
+func NewPasswordPolicy(cfg config.ServiceConfiguration) *PasswordPolicy {
+	policy := cfg.Api.Auth.PasswordPolicy
+	if policy.MinLength <= 0 {
+		policy.MinLength = defaultMinPasswordLength
+	}
+	if policy.MaxLength <= 0 {
+		policy.MaxLength = defaultMaxPasswordLength
+	}
+
+	return &PasswordPolicy{
+		minLength:      policy.MinLength,
+		maxLength:      policy.MaxLength,
+		requireDigit:   policy.RequireDigit,
+		requireLetter:  policy.RequireLetter,
+		requireUpper:   policy.RequireUpper,
+		requireSymbol:  policy.RequireSymbol,
+		allowUnicode:   policy.AllowUnicode,
+		minStrength:    policy.MinStrength,
+		breachedCorpus: policy.BreachedCorpus,
+	}
+}
+
+// Validate returns a *PasswordError describing the first rule the password
+// breaks. userInputs (e.g. the email) are penalised by the strength check.
+func (p *PasswordPolicy) Validate(pass string, userInputs ...string) error {
+	if !utf8.ValidString(pass) {
+		return ErrPasswordBadSymbols
+	}
+
+	length := utf8.RuneCountInString(pass)
+	if length < p.minLength {
+		return &PasswordError{
+			code:    "password_too_short",
+			message: fmt.Sprintf("Password must be at least %d characters long", p.minLength),
+			params:  map[string]string{"min": strconv.Itoa(p.minLength)},
+		}
+	}
+	if length > p.maxLength {
+		return &PasswordError{
+			code:    "password_too_long",
+			message: fmt.Sprintf("Password must be at most %d characters long", p.maxLength),
+			params:  map[string]string{"max": strconv.Itoa(p.maxLength)},
+		}
+	}
+
+	var hasDigit, hasLetter, hasUpper, hasSymbol bool
+	for _, r := range pass {
+		if !p.allowedRune(r) {
+			return ErrPasswordBadSymbols
+		}
+		switch {
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsLetter(r):
+			hasLetter = true
+			hasUpper = hasUpper || unicode.IsUpper(r)
+		default:
+			hasSymbol = true
+		}
+	}
+
+	if p.requireDigit && !hasDigit {
+		return ErrPasswordNoDigit
+	}
+	if p.requireLetter && !hasLetter {
+		return ErrPasswordNoLetter
+	}
+	if p.requireUpper && !hasUpper {
+		return ErrPasswordNoUpper
+	}
+	if p.requireSymbol && !hasSymbol {
+		return ErrPasswordNoSymbol
+	}
+
+	if p.minStrength > 0 && zxcvbn.PasswordStrength(pass, inputWords(userInputs)).Score < p.minStrength {
+		return ErrPasswordTooWeak
+	}
+
+	breached, err := p.isBreached(pass)
+	if err != nil {
+		return err
+	}
+	if breached {
+		return ErrPasswordBreached
+	}
+
+	return nil
+}
+
+// inputWords adds the parts of the user inputs to them. zxcvbn only matches
+// whole inputs, so a password made of the name in an email would otherwise
+// pass as strong.
+func inputWords(userInputs []string) []string {
+	words := append([]string(nil), userInputs...)
+	for _, input := range userInputs {
+		for _, word := range strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if utf8.RuneCountInString(word) >= minInputWordLength {
+				words = append(words, word)
+			}
+		}
+		if local, _, ok := strings.Cut(input, "@"); ok {
+			words = append(words, local)
+		}
+	}
+	return words
+}
+
+func (p *PasswordPolicy) allowedRune(r rune) bool {
+	if p.allowUnicode {
+		return unicode.IsPrint(r)
+	}
+	return r >= 0x20 && r <= 0x7E
+}
+
+// isBreached looks the password up in a local corpus laid out the same way as
+// the Have I Been Pwned range API: the upper-case SHA-1 of the password is
+// split into a 5 character prefix naming the file <prefix>.txt and a suffix
+// that is searched for among its "SUFFIX:COUNT" lines. Only the prefix file
+// is ever opened, so the corpus can be arbitrarily large.
+func (p *PasswordPolicy) isBreached(pass string) (bool, error) {
+	if p.breachedCorpus == "" {
+		return false, nil
+	}
+
+	sum := sha1.Sum([]byte(pass))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:breachedPrefixLength], hash[breachedPrefixLength:]
+
+	file, err := os.Open(filepath.Join(p.breachedCorpus, prefix+".txt"))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			line = line[:i]
+		}
+		if strings.EqualFold(strings.TrimSpace(line), suffix) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"task-manager-backend/internal/app/config"
+	"testing"
+)
+
+// breachedCorpus lays out a corpus holding the passwords, and a directory in
+// place of the file of unreadable, so reading it fails.
+func breachedCorpus(t *testing.T, unreadable string, passwords ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	split := func(pass string) (string, string) {
+		sum := sha1.Sum([]byte(pass))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+		return hash[:breachedPrefixLength], hash[breachedPrefixLength:]
+	}
+	for _, pass := range passwords {
+		prefix, suffix := split(pass)
+		lines := "0018A45C4D1DEF81644B54AB7F969B88D65:1\n" + suffix + ":42\n"
+		if err := os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(lines), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prefix, _ := split(unreadable)
+	if err := os.Mkdir(filepath.Join(dir, prefix+".txt"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	corpus := breachedCorpus(t, "unreadable1", "breached1")
+	tooShort := func(min string) error {
+		return &PasswordError{code: "password_too_short", params: map[string]string{"min": min}}
+	}
+
+	for _, tt := range []struct {
+		name   string
+		policy func(*config.PasswordPolicy)
+		pass   string
+		inputs []string
+		want   error
+	}{
+		{"default rules", nil, "abc123", nil, nil},
+		{"too short", nil, "ab1", nil, tooShort("6")},
+		{"too long", nil, strings.Repeat("a1", 11), nil,
+			&PasswordError{code: "password_too_long", params: map[string]string{"max": "20"}}},
+		{"no digit", nil, "abcdef", nil, ErrPasswordNoDigit},
+		{"no letter", nil, "123456", nil, ErrPasswordNoLetter},
+		{"non-ASCII", nil, "пароль1", nil, ErrPasswordBadSymbols},
+		{"control character", nil, "abc\t123", nil, ErrPasswordBadSymbols},
+		{"invalid UTF-8", nil, "abc123\xff", nil, ErrPasswordBadSymbols},
+		{"unicode allowed", func(p *config.PasswordPolicy) { p.AllowUnicode = true }, "пароль1", nil, nil},
+		{"no upper", func(p *config.PasswordPolicy) { p.RequireUpper = true }, "abc123", nil, ErrPasswordNoUpper},
+		{"no symbol", func(p *config.PasswordPolicy) { p.RequireSymbol = true }, "abc123", nil, ErrPasswordNoSymbol},
+
+		// Each setting has its own default, so configuring one keeps the others.
+		{"min length keeps the other rules", func(p *config.PasswordPolicy) { p.MinLength = 10 }, "abcdefghij", nil, ErrPasswordNoDigit},
+		{"configured min length", func(p *config.PasswordPolicy) { p.MinLength = 10 }, "abcdefgh1", nil, tooShort("10")},
+		{"unset min length", func(p *config.PasswordPolicy) { p.MinLength = 0 }, "abc12", nil, tooShort("6")},
+
+		{"weak", func(p *config.PasswordPolicy) { p.MinStrength = 3 }, "password1", nil, ErrPasswordTooWeak},
+		{"strong", func(p *config.PasswordPolicy) { p.MinStrength = 3 }, "kV9#pLr2xT", nil, nil},
+		{"strong without the email", func(p *config.PasswordPolicy) { p.MinStrength = 3 }, "Qmvtrhalsk7", nil, nil},
+		{"made of the email", func(p *config.PasswordPolicy) { p.MinStrength = 3 }, "Qmvtrhalsk7", []string{"qmvtrhalsk@example.com"}, ErrPasswordTooWeak},
+
+		{"breached", func(p *config.PasswordPolicy) { p.BreachedCorpus = corpus }, "breached1", nil, ErrPasswordBreached},
+		{"not in its range file", func(p *config.PasswordPolicy) { p.BreachedCorpus = corpus }, "abc123", nil, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			if tt.policy != nil {
+				tt.policy(&cfg.Api.Auth.PasswordPolicy)
+			}
+
+			err := NewPasswordPolicy(cfg).Validate(tt.pass, tt.inputs...)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("got %v, want the password accepted", err)
+				}
+				return
+			}
+			var got, want *PasswordError
+			if !errors.As(err, &got) || !errors.As(tt.want, &want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if got.code != want.code || (want.params != nil && !reflect.DeepEqual(got.params, want.params)) {
+				t.Fatalf("got %s %v, want %s %v", got.code, got.params, want.code, want.params)
+			}
+		})
+	}
+}
+
+// A corpus that cant be read fails the check rather than letting a password
+// through unchecked.
+func TestPasswordPolicyUnreadableCorpus(t *testing.T) {
+	cfg := config.Default()
+	cfg.Api.Auth.PasswordPolicy.BreachedCorpus = breachedCorpus(t, "unreadable1")
+
+	err := NewPasswordPolicy(cfg).Validate("unreadable1")
+	var passErr *PasswordError
+	if err == nil || errors.As(err, &passErr) {
+		t.Fatalf("got %v, want the read error", err)
+	}
+}
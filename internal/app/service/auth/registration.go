@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/errs"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/invites"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/models/workspaces"
+	"time"
+)
+
+const (
+	RegistrationOpen   = "open"
+	RegistrationClosed = "closed"
+	RegistrationInvite = "invite"
+)
+
+const InviteKeyTime = 604800
+
+var (
+	ClosedRegistration = errs.New(errs.Forbidden, "registration_closed", "Registration is closed")
+	InviteRequired     = errs.New(errs.Forbidden, "invite_required", "Registration requires an invitation")
+	DomainNotAllowed   = errs.New(errs.Forbidden, "email_domain_not_allowed", "Registration from this email domain is not allowed")
+	InvalidInvite      = errs.New(errs.Invalid, "invalid_invite", "Invalid invitation")
+	NotAllowed         = errs.New(errs.Forbidden, "not_allowed", "Not enough rights")
+)
+
+type RegistrationPolicy struct {
+	mode           string
+	allowedDomains map[string]struct{}
+	deniedDomains  map[string]struct{}
+}
+
+func NewRegistrationPolicy(cfg config.ServiceConfiguration) (*RegistrationPolicy, error) {
+	regCfg := cfg.Api.Auth.Registration
+
+	mode := regCfg.Mode
+	switch mode {
+	case "":
+		mode = RegistrationOpen
+	case RegistrationOpen, RegistrationClosed, RegistrationInvite:
+	default:
+		return nil, fmt.Errorf("NewRegistrationPolicy: unknown registration mode %q", mode)
+	}
+
+	return &RegistrationPolicy{
+		mode:           mode,
+		allowedDomains: domainSet(regCfg.AllowedDomains),
+		deniedDomains:  domainSet(regCfg.DeniedDomains),
+	}, nil
+}
+
+func domainSet(domains []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(domains))
+	for _, domain := range domains {
+		set[strings.ToLower(strings.TrimSpace(domain))] = struct{}{}
+	}
+	return set
+}
+
+// domainAllowed applies the deny list first, then the allow list if one is
+// configured.
+func (p *RegistrationPolicy) domainAllowed(email users.Email) bool {
+	at := strings.LastIndexByte(string(email), '@')
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(string(email[at+1:]))
+
+	if _, denied := p.deniedDomains[domain]; denied {
+		return false
+	}
+	if len(p.allowedDomains) == 0 {
+		return true
+	}
+	_, allowed := p.allowedDomains[domain]
+	return allowed
+}
+
+// checkRegistration decides whether email may register under the configured
+// mode. A valid invitation is returned without accepting it, which is left
+// to the transaction creating the user; it bypasses the domain lists since
+// its inviter vouched for the address explicitly.
+func (s *Service) checkRegistration(ctx context.Context, email users.Email, inviteToken string) (*invites.Invite, error) {
+	switch s.registration.mode {
+	case RegistrationClosed:
+		return nil, ClosedRegistration
+	case RegistrationInvite:
+		if inviteToken == "" {
+			return nil, InviteRequired
+		}
+	}
+
+	if inviteToken == "" {
+		if !s.registration.domainAllowed(email) {
+			return nil, DomainNotAllowed
+		}
+		return nil, nil
+	}
+
+	invite, err := s.repository.GetInvite(ctx, inviteToken)
+	if err != nil {
+		return nil, err
+	}
+	if invite.Token != inviteToken || !invite.Pending() || !strings.EqualFold(string(invite.Email), string(email)) {
+		return nil, InvalidInvite
+	}
+
+	return &invite, nil
+}
+
+// createInvitedUser stores the user and accepts the invitation, joining its
+// workspace if it has one.
+func (s *Service) createInvitedUser(ctx context.Context, user users.User, invite invites.Invite) error {
+	announce := make([]events.Event, 0, 1)
+	if invite.WorkspaceID != nil {
+		event := events.NewEvent(events.MemberAdded, invite.InviterID, []users.ID{user.ID}, map[string]string{
+			events.PayloadUser: strconv.FormatUint(uint64(user.ID), 10),
+			events.PayloadRole: string(invite.Role),
+		})
+		event.WorkspaceID = *invite.WorkspaceID
+		announce = append(announce, event)
+	}
+
+	err := s.repository.CreateInvitedUser(ctx, user, invite, announce...)
+	if errors.Is(err, invites.ErrNotPending) {
+		return InvalidInvite
+	}
+	return err
+}
+
+// CreateInvite invites email to register. Admins may invite anyone; owners
+// and admins of a workspace may invite into it, and the invitee joins it with
+// role, a member by default, on registering.
+func (s *Service) CreateInvite(ctx context.Context, inviterID users.ID, email users.Email, workspaceID workspaces.ID, role workspaces.Role) error {
+	if s.registration.mode == RegistrationClosed {
+		return ClosedRegistration
+	}
+	if !users.ValidateEmail(email) {
+		return InvalidEmail
+	}
+	if workspaceID == 0 && role != "" {
+		return InvalidData
+	}
+	if role == "" {
+		role = workspaces.RoleMember
+	}
+	if !workspaces.ValidateRole(role) || role == workspaces.RoleOwner {
+		return InvalidData
+	}
+
+	inviter, err := s.repository.GetUserByUserID(ctx, inviterID)
+	if err != nil {
+		return err
+	}
+
+	invite := invites.NewInvite(email, inviterID, InviteKeyTime*time.Second)
+	if workspaceID == 0 {
+		if inviter.Status != users.StatusAdmin {
+			return NotAllowed
+		}
+	} else {
+		member, err := s.repository.GetWorkspaceMember(ctx, workspaceID, inviterID)
+		if err != nil {
+			return err
+		}
+		if member.UserID != inviterID || !member.Role.CanManageMembers() {
+			return NotAllowed
+		}
+		invite.WorkspaceID = &workspaceID
+		invite.Role = role
+	}
+
+	if user, _ := s.repository.GetUserByEmail(ctx, email); user.Email == email {
+		return UserAlreadyExist
+	}
+
+	if err = s.repository.CreateInvite(ctx, invite); err != nil {
+		return err
+	}
+
+	// The invitee has no locale yet; the inviter's is the best guess.
+	return s.publishMail(ctx, events.UserInvited, inviterID, email, inviter.Locale, invite.Token)
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"task-manager-backend/internal/app/tracing"
+)
+
+const tracerName = "task-manager-backend/internal/app/service/auth"
+
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name)
+}
+
+// endSpan records the outcome on the span. Expected failures such as wrong
+// credentials are not errors of the span.
+func endSpan(span trace.Span, err error) {
+	result := outcome(err)
+	span.SetAttributes(attribute.String("auth.outcome", result))
+	if result != outcomeError {
+		err = nil
+	}
+	tracing.End(span, err)
+}
+
+// hash hashes the password in its own span, since it is deliberately slow.
+func (s *Service) hash(ctx context.Context, password string) (string, error) {
+	_, span := startSpan(ctx, "password.hash")
+	hash, err := s.hasher.Hash(password)
+	tracing.End(span, err)
+	return hash, err
+}
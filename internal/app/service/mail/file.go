@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const defaultMaildir = "maildir"
+
+// FileTransport writes every message into a maildir, where any mail client
+// can open it. Messages are written to tmp/ and moved to new/, so a reader
+// never sees a half-written file.
+type FileTransport struct {
+	dir string
+}
+
+func NewFileTransport(dir string) (*FileTransport, error) {
+	if dir == "" {
+		dir = defaultMaildir
+	}
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &FileTransport{dir: dir}, nil
+}
+
+// Ping checks that the maildir is still there.
+func (t *FileTransport) Ping(ctx context.Context) error {
+	_, err := os.Stat(filepath.Join(t.dir, "new"))
+	return err
+}
+
+func (t *FileTransport) Send(ctx context.Context, from string, to []string, data []byte) error {
+	if len(to) == 0 {
+		return errors.New("mail: no recipients")
+	}
+
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+	hostname, _ := os.Hostname()
+	name := strconv.FormatInt(time.Now().UnixNano(), 10) + "." + hex.EncodeToString(random) + "." + hostname
+
+	tmp := filepath.Join(t.dir, "tmp", name)
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(t.dir, "new", name))
+}
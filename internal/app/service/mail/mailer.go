@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"context"
+	"encoding/json"
+	"task-manager-backend/internal/app/models/jobs"
+	"task-manager-backend/internal/app/queue"
+)
+
+const SendJob jobs.Kind = "mail.send"
+
+func NewMailer(queue *queue.Queue, renderer *Renderer) *Mailer {
+	return &Mailer{
+		queue:    queue,
+		renderer: renderer,
+	}
+}
+
+// Mailer queues mails instead of sending them, so requests do not wait on
+// the SMTP server and a failed send is retried. Mails are rendered when they
+// are queued, so a broken template fails the caller rather than the job.
+type Mailer struct {
+	queue    *queue.Queue
+	renderer *Renderer
+}
+
+func (m *Mailer) Send(ctx context.Context, template, locale, to string, data map[string]string) error {
+	msg, err := m.renderer.Render(template, locale, to, data)
+	if err != nil {
+		return err
+	}
+	return m.queue.Enqueue(ctx, SendJob, msg)
+}
+
+// RegisterJobs lets the queue workers send the queued mails.
+func RegisterJobs(q *queue.Queue, sender *Sender) {
+	q.Register(SendJob, func(ctx context.Context, payload json.RawMessage) error {
+		var msg Message
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			return queue.Permanent(err)
+		}
+		return sender.Send(ctx, msg)
+	})
+}
@@ -0,0 +1,126 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+)
+
+const memoryLimit = 100
+
+var decoder = new(mime.WordDecoder)
+
+// Stored is a message kept by MemoryTransport.
+type Stored struct {
+	ID      int       `json:"id"`
+	From    string    `json:"from"`
+	To      []string  `json:"to"`
+	Subject string    `json:"subject"`
+	SentAt  time.Time `json:"sent_at"`
+	Text    string    `json:"text"`
+	HTML    string    `json:"html"`
+	Raw     string    `json:"-"`
+}
+
+// MemoryTransport keeps the last messages in memory instead of sending
+// them. It backs the /dev/mailbox viewer.
+type MemoryTransport struct {
+	mu       sync.RWMutex
+	lastID   int
+	messages []Stored
+}
+
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{}
+}
+
+func (t *MemoryTransport) Send(ctx context.Context, from string, to []string, data []byte) error {
+	stored := parseStored(data)
+	stored.From = from
+	stored.To = to
+	stored.SentAt = time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastID++
+	stored.ID = t.lastID
+	t.messages = append(t.messages, stored)
+	if len(t.messages) > memoryLimit {
+		t.messages = t.messages[len(t.messages)-memoryLimit:]
+	}
+	return nil
+}
+
+// Messages returns the kept messages, newest first.
+func (t *MemoryTransport) Messages() []Stored {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := make([]Stored, 0, len(t.messages))
+	for i := len(t.messages) - 1; i >= 0; i-- {
+		result = append(result, t.messages[i])
+	}
+	return result
+}
+
+func (t *MemoryTransport) Message(id int) (Stored, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, stored := range t.messages {
+		if stored.ID == id {
+			return stored, true
+		}
+	}
+	return Stored{}, false
+}
+
+func (t *MemoryTransport) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.messages = nil
+}
+
+// parseStored extracts the subject and the text and HTML parts, so the
+// viewer shows what a mail client would.
+func parseStored(data []byte) Stored {
+	stored := Stored{Raw: string(data)}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return stored
+	}
+	stored.Subject, _ = decoder.DecodeHeader(msg.Header.Get("Subject"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		body, _ := io.ReadAll(msg.Body)
+		stored.Text = string(body)
+		return stored
+	}
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err != nil {
+			break
+		}
+		body, _ := io.ReadAll(part)
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "text/plain":
+			stored.Text = string(body)
+		case "text/html":
+			stored.HTML = string(body)
+		}
+	}
+	return stored
+}
@@ -0,0 +1,93 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Compose builds a multipart/alternative message with a plain text and an
+// HTML part. Non-ASCII headers are RFC 2047 encoded, bodies are sent as
+// quoted-printable UTF-8.
+func Compose(fromName, from string, msg Message) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	if err := writePart(parts, "text/plain; charset=utf-8", msg.Text); err != nil {
+		return nil, err
+	}
+	if err := writePart(parts, "text/html; charset=utf-8", msg.HTML); err != nil {
+		return nil, err
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	sender := mail.Address{Name: fromName, Address: from}
+	recipient := mail.Address{Address: msg.To}
+
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", sender.String())
+	header("To", recipient.String())
+	header("Subject", mime.BEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageID(from))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+func writePart(parts *multipart.Writer, contentType, content string) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	part, err := parts.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	encoder := quotedprintable.NewWriter(part)
+	if _, err := encoder.Write([]byte(content)); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// messageCount tells apart the Message-IDs made in the same nanosecond
+// when there is no randomness to be had.
+var messageCount uint64
+
+// messageID makes a unique Message-ID. Should reading random bytes fail, it
+// falls back to the time and a counter.
+func messageID(from string) string {
+	unique := make([]byte, 12)
+	var id string
+	if _, err := rand.Read(unique); err == nil {
+		id = hex.EncodeToString(unique)
+	} else {
+		id = strconv.FormatInt(time.Now().UnixNano(), 36) + "." + strconv.FormatUint(atomic.AddUint64(&messageCount, 1), 36)
+	}
+
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	return "<" + id + "@" + domain + ">"
+}
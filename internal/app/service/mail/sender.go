@@ -1,24 +1,74 @@
 package mail
 
 import (
-	"net/smtp"
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/metrics"
+	"task-manager-backend/internal/app/tracing"
+	"time"
 )
 
+const tracerName = "task-manager-backend/internal/app/service/mail"
+
+// Sender composes messages and hands them to the configured transport.
 type Sender struct {
-	auth smtp.Auth
-	addr string
-	from string
+	transport Transport
+	from      string
+	name      string
+	duration  *prometheus.HistogramVec
 }
 
-func NewSender(cfg config.ServiceConfiguration) *Sender {
+func NewSender(cfg config.ServiceConfiguration, transport Transport, registerer prometheus.Registerer) *Sender {
 	return &Sender{
-		auth: smtp.PlainAuth("", cfg.From, cfg.Token, cfg.Host),
-		addr: cfg.Addr,
-		from: cfg.From,
+		transport: transport,
+		from:      cfg.From,
+		name:      cfg.FromName,
+		duration: promauto.With(registerer).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "mail",
+			Name:      "send_duration_seconds",
+			Help:      "Time to hand a mail to the transport, by result.",
+		}, []string{"transport", "result"}),
+	}
+}
+
+func (s *Sender) Send(ctx context.Context, msg Message) error {
+	data, err := Compose(s.name, s.from, msg)
+	if err != nil {
+		return err
 	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "mail.send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("mail.transport", s.transportName())))
+
+	started := time.Now()
+	err = s.transport.Send(ctx, s.from, []string{msg.To}, data)
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	s.duration.WithLabelValues(s.transportName(), result).Observe(time.Since(started).Seconds())
+	tracing.End(span, err)
+	return err
 }
 
-func (s *Sender) SendMail(email, msg string) error {
-	return smtp.SendMail(s.addr, s.auth, s.from, []string{email}, []byte(msg))
+func (s *Sender) transportName() string {
+	switch s.transport.(type) {
+	case *SMTPTransport:
+		return TransportSMTP
+	case *FileTransport:
+		return TransportFile
+	case LogTransport:
+		return TransportLog
+	case *MemoryTransport:
+		return TransportMemory
+	default:
+		return "other"
+	}
 }
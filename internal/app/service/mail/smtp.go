@@ -0,0 +1,144 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"task-manager-backend/internal/app/config"
+	"time"
+)
+
+// TLS modes of the SMTP transport.
+const (
+	TLSStartTLS = "starttls"
+	TLSImplicit = "implicit"
+	TLSNone     = "none"
+
+	defaultSMTPTimeout = 30 * time.Second
+)
+
+// SMTPTransport sends mail through an SMTP server. STARTTLS is required
+// unless TLS is implicit (usually port 465) or explicitly turned off, so
+// credentials never go over a plain connection by accident.
+type SMTPTransport struct {
+	addr    string
+	host    string
+	tls     string
+	timeout time.Duration
+	auth    smtp.Auth
+}
+
+func NewSMTPTransport(cfg config.Mail) (*SMTPTransport, error) {
+	host := cfg.Host
+	if host == "" {
+		var err error
+		if host, _, err = net.SplitHostPort(cfg.Addr); err != nil {
+			return nil, fmt.Errorf("mail: bad smtp address %q: %w", cfg.Addr, err)
+		}
+	}
+
+	mode := cfg.TLS
+	if mode == "" {
+		mode = TLSStartTLS
+	}
+	if mode != TLSStartTLS && mode != TLSImplicit && mode != TLSNone {
+		return nil, fmt.Errorf("mail: unknown tls mode %q", cfg.TLS)
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultSMTPTimeout
+	}
+
+	transport := &SMTPTransport{
+		addr:    cfg.Addr,
+		host:    host,
+		tls:     mode,
+		timeout: timeout,
+	}
+	if cfg.Token != "" {
+		transport.auth = smtp.PlainAuth("", cfg.From, cfg.Token, host)
+	}
+	return transport, nil
+}
+
+func (t *SMTPTransport) Send(ctx context.Context, from string, to []string, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	conn, err := t.dial(ctx)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, t.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if t.tls == TLSStartTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: t.host}); err != nil {
+			return err
+		}
+	}
+	if t.auth != nil {
+		if err := client.Auth(t.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// Ping connects to the server and waits for its greeting.
+func (t *SMTPTransport) Ping(ctx context.Context) error {
+	conn, err := t.dial(ctx)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, t.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	return client.Quit()
+}
+
+func (t *SMTPTransport) dial(ctx context.Context) (net.Conn, error) {
+	if t.tls == TLSImplicit {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: t.host}}
+		return dialer.DialContext(ctx, "tcp", t.addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", t.addr)
+}
@@ -0,0 +1,248 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"go.uber.org/zap"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/errs"
+	"task-manager-backend/internal/app/models/users"
+	texttemplate "text/template"
+)
+
+// Template names. Every locale directory has a <name>.tmpl defining the
+// "subject", "text" and "html" blocks; layout.tmpl holds the shared parts of
+// the HTML version.
+const (
+	ConfirmEmail    = "confirm_email"
+	RestorePassword = "restore_password"
+	Invite          = "invite"
+	Notification    = "notification"
+
+	layoutTemplate  = "layout"
+	templateExt     = ".tmpl"
+	defaultBaseURL  = "http://localhost:5173"
+	subjectTemplate = "subject"
+	textTemplate    = "text"
+	htmlTemplate    = "html"
+)
+
+var UnknownTemplate = errs.New(errs.NotFound, "template_not_found", "Unknown mail template")
+
+//go:embed templates
+var templateFiles embed.FS
+
+// Samples is the data templates are previewed with.
+var Samples = map[string]map[string]string{
+	ConfirmEmail:    {"Token": "sample-confirmation-token"},
+	RestorePassword: {"Token": "sample-restore-token"},
+	Invite:          {"Token": "sample-invite-token"},
+	Notification:    {"Text": "Вам назначена задача"},
+}
+
+// Message is a rendered mail ready to be sent.
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+type localeTemplates struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+// Renderer renders the templates in the recipient's locale. The embedded
+// templates are used unless mail.templates_dir points to others, which are
+// parsed again when the configuration is reloaded. Links in mails point to
+// the configured public URL of the frontend.
+type Renderer struct {
+	baseURL string
+	logger  *zap.Logger
+
+	mu      sync.RWMutex
+	locales map[string]localeTemplates
+}
+
+func NewRenderer(cfg config.ServiceConfiguration, store *config.Store, logger *zap.Logger) (*Renderer, error) {
+	baseURL := strings.TrimRight(cfg.PublicURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	locales, err := loadTemplates(cfg.Mail.TemplatesDir)
+	if err != nil {
+		return nil, err
+	}
+	renderer := &Renderer{
+		baseURL: baseURL,
+		locales: locales,
+		logger:  logger,
+	}
+
+	store.Check(func(cfg config.ServiceConfiguration) error {
+		_, err := loadTemplates(cfg.Mail.TemplatesDir)
+		return err
+	})
+	store.Subscribe(renderer.reload)
+	return renderer, nil
+}
+
+// reload parses the templates again, since the files may have changed even
+// when the directory did not.
+func (r *Renderer) reload(cfg config.ServiceConfiguration) {
+	locales, err := loadTemplates(cfg.Mail.TemplatesDir)
+	if err != nil {
+		r.logger.Error("Mail: Cant reload templates, keeping the current ones", zap.Error(err))
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.locales = locales
+}
+
+// loadTemplates parses the templates in dir, or the embedded ones when dir
+// is empty.
+func loadTemplates(dir string) (map[string]localeTemplates, error) {
+	var files fs.FS = os.DirFS(dir)
+	if dir == "" {
+		var err error
+		if files, err = fs.Sub(templateFiles, "templates"); err != nil {
+			return nil, err
+		}
+	}
+
+	dirs, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	locales := make(map[string]localeTemplates)
+	for _, entry := range dirs {
+		if !entry.IsDir() {
+			continue
+		}
+		parsed, err := parseLocale(files, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		locales[entry.Name()] = parsed
+	}
+
+	if _, ok := locales[users.DefaultLocale]; !ok {
+		return nil, errors.New("mail: no templates for the default locale " + users.DefaultLocale)
+	}
+	return locales, nil
+}
+
+func parseLocale(files fs.FS, dir string) (localeTemplates, error) {
+	parsed := localeTemplates{
+		text: make(map[string]*texttemplate.Template),
+		html: make(map[string]*htmltemplate.Template),
+	}
+
+	layout := path.Join(dir, layoutTemplate+templateExt)
+	names, err := fs.Glob(files, path.Join(dir, "*"+templateExt))
+	if err != nil {
+		return parsed, err
+	}
+
+	for _, file := range names {
+		if file == layout {
+			continue
+		}
+		name := strings.TrimSuffix(path.Base(file), templateExt)
+
+		text, err := texttemplate.ParseFS(files, layout, file)
+		if err != nil {
+			return parsed, err
+		}
+		html, err := htmltemplate.ParseFS(files, layout, file)
+		if err != nil {
+			return parsed, err
+		}
+
+		parsed.text[name] = text
+		parsed.html[name] = html
+	}
+	return parsed, nil
+}
+
+// Templates returns the names of the available templates.
+func (r *Renderer) Templates() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0)
+	for name := range r.locales[users.DefaultLocale].text {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render renders the template for the recipient. A locale without its own
+// templates falls back to its base language and then to the default locale.
+func (r *Renderer) Render(name, locale, to string, data map[string]string) (Message, error) {
+	templates := r.locale(locale)
+	text, ok := templates.text[name]
+	if !ok {
+		return Message{}, UnknownTemplate
+	}
+	html := templates.html[name]
+
+	values := map[string]string{
+		"BaseURL": r.baseURL,
+		"Email":   to,
+	}
+	for key, value := range data {
+		values[key] = value
+	}
+
+	msg := Message{To: to}
+	var buf bytes.Buffer
+
+	if err := text.ExecuteTemplate(&buf, subjectTemplate, values); err != nil {
+		return Message{}, err
+	}
+	msg.Subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := text.ExecuteTemplate(&buf, textTemplate, values); err != nil {
+		return Message{}, err
+	}
+	msg.Text = buf.String()
+
+	buf.Reset()
+	if err := html.ExecuteTemplate(&buf, htmlTemplate, values); err != nil {
+		return Message{}, err
+	}
+	msg.HTML = buf.String()
+
+	return msg, nil
+}
+
+func (r *Renderer) locale(locale string) localeTemplates {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if templates, ok := r.locales[locale]; ok {
+		return templates
+	}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		if templates, ok := r.locales[base]; ok {
+			return templates
+		}
+	}
+	return r.locales[users.DefaultLocale]
+}
@@ -0,0 +1,52 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"task-manager-backend/internal/app/config"
+)
+
+// Transports selectable with mail.transport.
+const (
+	TransportSMTP   = "smtp"
+	TransportFile   = "file"
+	TransportLog    = "log"
+	TransportMemory = "memory"
+)
+
+// Transport delivers a composed message.
+type Transport interface {
+	Send(ctx context.Context, from string, to []string, data []byte) error
+}
+
+// Pinger is implemented by transports that can check whether they are able
+// to deliver without sending anything.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks the transport if it supports checking.
+func Ping(ctx context.Context, transport Transport) error {
+	if pinger, ok := transport.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// NewTransport returns the transport chosen in the config. SMTP is the
+// default; the others are meant for development and tests.
+func NewTransport(cfg config.ServiceConfiguration, logger *zap.Logger) (Transport, error) {
+	switch cfg.Mail.Transport {
+	case TransportSMTP, "":
+		return NewSMTPTransport(cfg.Mail)
+	case TransportFile:
+		return NewFileTransport(cfg.Mail.Dir)
+	case TransportLog:
+		return NewLogTransport(logger), nil
+	case TransportMemory:
+		return NewMemoryTransport(), nil
+	default:
+		return nil, fmt.Errorf("mail: unknown transport %q", cfg.Mail.Transport)
+	}
+}
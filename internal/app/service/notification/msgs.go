@@ -0,0 +1,36 @@
+package notification
+
+import (
+	"strings"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/users"
+)
+
+// eventTexts are the lines describing an event in notification mails, by
+// locale.
+var eventTexts = map[string]map[events.Type]string{
+	"ru": {
+		events.TaskAssigned:      "Вам назначена задача",
+		events.TaskStatusChanged: "Изменился статус задачи",
+		events.TaskDueSoon:       "Скоро наступит срок выполнения задачи",
+		events.CommentCreated:    "Новый комментарий к задаче",
+		events.UserMentioned:     "Вас упомянули в комментарии",
+	},
+	"en": {
+		events.TaskAssigned:      "A task was assigned to you",
+		events.TaskStatusChanged: "A task status has changed",
+		events.TaskDueSoon:       "A task is due soon",
+		events.CommentCreated:    "New comment on a task",
+		events.UserMentioned:     "You were mentioned in a comment",
+	},
+}
+
+func eventText(locale string, eventType events.Type) string {
+	base, _, _ := strings.Cut(locale, "-")
+	for _, candidate := range []string{locale, base, users.DefaultLocale} {
+		if texts, ok := eventTexts[candidate]; ok {
+			return texts[eventType]
+		}
+	}
+	return string(eventType)
+}
@@ -0,0 +1,211 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/multierr"
+	"task-manager-backend/internal/app/errs"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/notifications"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/service/mail"
+)
+
+const (
+	consumer       = "notification"
+	DefaultLimit   = 20
+	MaxLimit       = 100
+	DefaultChannel = notifications.InApp
+)
+
+var (
+	InvalidData = errs.New(errs.Invalid, errs.CodeInvalid, "Invalid data")
+	NotFound    = errs.New(errs.NotFound, "notification_not_found", "Notification not found")
+)
+
+// NotifiableEvents are the event types users receive notifications about.
+var NotifiableEvents = []events.Type{
+	events.TaskAssigned,
+	events.TaskStatusChanged,
+	events.TaskDueSoon,
+	events.CommentCreated,
+	events.UserMentioned,
+}
+
+type Repository interface {
+	CreateNotification(context.Context, notifications.Notification) error
+	GetNotifications(context.Context, users.ID, bool, uint64, uint64) ([]notifications.Notification, error)
+	CountUnreadNotifications(context.Context, users.ID) (int64, error)
+	MarkNotificationRead(context.Context, users.ID, notifications.ID) (bool, error)
+	MarkAllNotificationsRead(context.Context, users.ID) error
+	GetNotificationPreferences(context.Context, users.ID) ([]notifications.Preference, error)
+	SetNotificationPreference(context.Context, users.ID, notifications.Preference) error
+
+	GetUserByUserID(context.Context, users.ID) (users.User, error)
+}
+
+func NewService(repository Repository, mailer *mail.Mailer, outbox events.OutboxRepository) *Service {
+	return &Service{
+		repository: repository,
+		mailer:     mailer,
+		outbox:     outbox,
+	}
+}
+
+type Service struct {
+	repository Repository
+	mailer     *mail.Mailer
+	outbox     events.OutboxRepository
+}
+
+// RegisterHandlers subscribes the service to every notifiable event.
+func RegisterHandlers(bus *events.Bus, service *Service) {
+	for _, eventType := range NotifiableEvents {
+		bus.Subscribe(eventType, service.handle)
+	}
+}
+
+// handle delivers the event to each recipient through the channel they chose
+// for its type. The actor is never notified about their own action.
+//
+// A failure for one recipient gets the whole event handled again, so each
+// recipient is tracked as a consumer of its own and the ones already
+// notified are skipped.
+func (s *Service) handle(ctx context.Context, event events.Event) error {
+	var err error
+	for _, userID := range event.Recipients {
+		if userID == event.ActorID {
+			continue
+		}
+		deliver := func(ctx context.Context, event events.Event) error {
+			return s.deliver(ctx, userID, event)
+		}
+		err = multierr.Append(err, events.Once(recipientConsumer(userID), s.outbox, deliver)(ctx, event))
+	}
+	return err
+}
+
+func recipientConsumer(userID users.ID) string {
+	return fmt.Sprintf("%s:%d", consumer, userID)
+}
+
+func (s *Service) deliver(ctx context.Context, userID users.ID, event events.Event) error {
+	channel, err := s.channel(ctx, userID, event.Type)
+	if err != nil {
+		return err
+	}
+
+	switch channel {
+	case notifications.InApp:
+		return s.repository.CreateNotification(ctx, notifications.NewNotification(userID, event))
+	case notifications.Email:
+		user, err := s.repository.GetUserByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return s.mailer.Send(ctx, mail.Notification, user.Locale, string(user.Email), map[string]string{
+			"Text": eventText(user.Locale, event.Type),
+		})
+	default:
+		return nil
+	}
+}
+
+func (s *Service) channel(ctx context.Context, userID users.ID, eventType events.Type) (notifications.Channel, error) {
+	preferences, err := s.repository.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, preference := range preferences {
+		if preference.EventType == eventType {
+			return preference.Channel, nil
+		}
+	}
+	return DefaultChannel, nil
+}
+
+func (s *Service) List(ctx context.Context, userID users.ID, unreadOnly bool, limit, offset uint64) ([]notifications.Notification, int64, error) {
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	list, err := s.repository.GetNotifications(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	unread, err := s.repository.CountUnreadNotifications(ctx, userID)
+	return list, unread, err
+}
+
+func (s *Service) UnreadCount(ctx context.Context, userID users.ID) (int64, error) {
+	return s.repository.CountUnreadNotifications(ctx, userID)
+}
+
+func (s *Service) MarkRead(ctx context.Context, userID users.ID, notificationID notifications.ID) error {
+	found, err := s.repository.MarkNotificationRead(ctx, userID, notificationID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return NotFound
+	}
+	return nil
+}
+
+func (s *Service) MarkAllRead(ctx context.Context, userID users.ID) error {
+	return s.repository.MarkAllNotificationsRead(ctx, userID)
+}
+
+// Preferences returns the channel for every notifiable event type, filling
+// in the default for types the user has not configured.
+func (s *Service) Preferences(ctx context.Context, userID users.ID) ([]notifications.Preference, error) {
+	stored, err := s.repository.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make(map[events.Type]notifications.Channel, len(stored))
+	for _, preference := range stored {
+		channels[preference.EventType] = preference.Channel
+	}
+
+	result := make([]notifications.Preference, 0, len(NotifiableEvents))
+	for _, eventType := range NotifiableEvents {
+		channel, ok := channels[eventType]
+		if !ok {
+			channel = DefaultChannel
+		}
+		result = append(result, notifications.Preference{EventType: eventType, Channel: channel})
+	}
+	return result, nil
+}
+
+func (s *Service) SetPreferences(ctx context.Context, userID users.ID, preferences []notifications.Preference) ([]notifications.Preference, error) {
+	for _, preference := range preferences {
+		if !isNotifiable(preference.EventType) || !notifications.ValidateChannel(preference.Channel) {
+			return nil, InvalidData
+		}
+	}
+
+	for _, preference := range preferences {
+		if err := s.repository.SetNotificationPreference(ctx, userID, preference); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.Preferences(ctx, userID)
+}
+
+func isNotifiable(eventType events.Type) bool {
+	for _, notifiable := range NotifiableEvents {
+		if notifiable == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/jobs"
+	"task-manager-backend/internal/app/models/notifications"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/queue"
+	"task-manager-backend/internal/app/service/mail"
+	"testing"
+)
+
+// memoryRepository keeps notifications per user and fails creating them for
+// the users in failing.
+type memoryRepository struct {
+	Repository
+	users         map[users.ID]users.User
+	preferences   map[users.ID][]notifications.Preference
+	notifications map[users.ID][]notifications.Notification
+	failing       map[users.ID]bool
+}
+
+func (r *memoryRepository) CreateNotification(_ context.Context, notification notifications.Notification) error {
+	if r.failing[notification.UserID] {
+		return errors.New("unavailable")
+	}
+	r.notifications[notification.UserID] = append(r.notifications[notification.UserID], notification)
+	return nil
+}
+
+func (r *memoryRepository) GetNotificationPreferences(_ context.Context, userID users.ID) ([]notifications.Preference, error) {
+	return r.preferences[userID], nil
+}
+
+func (r *memoryRepository) GetUserByUserID(_ context.Context, userID users.ID) (users.User, error) {
+	return r.users[userID], nil
+}
+
+type memoryOutbox struct {
+	events.OutboxRepository
+	processed map[string]bool
+}
+
+func (o *memoryOutbox) EventProcessed(_ context.Context, consumer, eventID string) (bool, error) {
+	return o.processed[consumer+"/"+eventID], nil
+}
+
+func (o *memoryOutbox) MarkEventProcessed(_ context.Context, consumer, eventID string) error {
+	o.processed[consumer+"/"+eventID] = true
+	return nil
+}
+
+// memoryJobs counts the queued mails.
+type memoryJobs struct {
+	queue.Repository
+	created int
+}
+
+func (r *memoryJobs) CreateJob(context.Context, jobs.Job) (bool, error) {
+	r.created++
+	return true, nil
+}
+
+// An event is handled again when delivering it to one recipient fails. The
+// recipients that were already notified must not get it twice.
+func TestHandleNotifiesEachRecipientOnce(t *testing.T) {
+	ctx := context.Background()
+
+	inApp, byEmail, failing := users.ID(1), users.ID(2), users.ID(3)
+	repository := &memoryRepository{
+		users: map[users.ID]users.User{
+			byEmail: {ID: byEmail, Email: "user@example.com", Locale: users.DefaultLocale},
+		},
+		preferences: map[users.ID][]notifications.Preference{
+			byEmail: {{EventType: events.TaskAssigned, Channel: notifications.Email}},
+		},
+		notifications: make(map[users.ID][]notifications.Notification),
+		failing:       map[users.ID]bool{failing: true},
+	}
+	queued := &memoryJobs{}
+	renderer, err := mail.NewRenderer(config.Default(), config.NewStore(config.Default(), ""), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewService(repository, mail.NewMailer(queue.NewQueue(queued, zap.NewNop()), renderer), &memoryOutbox{processed: make(map[string]bool)})
+
+	event := events.NewEvent(events.TaskAssigned, 0, []users.ID{inApp, byEmail, failing}, nil)
+	if err := service.handle(ctx, event); err == nil {
+		t.Fatal("handle succeeded, want the failure of the third recipient")
+	}
+
+	repository.failing = nil
+	if err := service.handle(ctx, event); err != nil {
+		t.Fatalf("handle again: %v", err)
+	}
+
+	if got := len(repository.notifications[inApp]); got != 1 {
+		t.Errorf("in-app recipient got %d notifications, want 1", got)
+	}
+	if queued.created != 1 {
+		t.Errorf("queued %d mails, want 1", queued.created)
+	}
+	if got := len(repository.notifications[failing]); got != 1 {
+		t.Errorf("recipient that failed got %d notifications, want 1", got)
+	}
+}
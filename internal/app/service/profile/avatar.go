@@ -0,0 +1,122 @@
+package profile
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// AvatarSizes are the square sizes every uploaded avatar is rendered in.
+var AvatarSizes = []int{64, 128, 256}
+
+const (
+	MaxAvatarBytes  = 5 << 20
+	maxAvatarPixels = 40_000_000
+)
+
+type AvatarStorage struct {
+	dir       string
+	urlPrefix string
+}
+
+func (s *AvatarStorage) fileName(key string, size int) string {
+	return fmt.Sprintf("%s_%d.png", key, size)
+}
+
+func (s *AvatarStorage) URLs(key string) map[string]string {
+	if key == "" {
+		return nil
+	}
+
+	urls := make(map[string]string, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		urls[strconv.Itoa(size)] = s.urlPrefix + "/" + s.fileName(key, size)
+	}
+	return urls
+}
+
+// Save decodes a JPEG, PNG or GIF image, crops it to a centred square and
+// stores it resized to every size in AvatarSizes. It returns the key the
+// files are stored under.
+func (s *AvatarStorage) Save(r io.Reader) (string, error) {
+	config, _, err := image.DecodeConfig(io.LimitReader(r, MaxAvatarBytes))
+	if err != nil {
+		return "", InvalidImage
+	}
+	if config.Width*config.Height > maxAvatarPixels {
+		return "", InvalidImage
+	}
+	if seeker, ok := r.(io.Seeker); ok {
+		if _, err = seeker.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+	}
+
+	src, _, err := image.Decode(io.LimitReader(r, MaxAvatarBytes))
+	if err != nil {
+		return "", InvalidImage
+	}
+	src = cropSquare(src)
+
+	key := uuid.New().String()
+	for _, size := range AvatarSizes {
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+		if err = s.write(s.fileName(key, size), dst); err != nil {
+			s.Delete(key)
+			return "", err
+		}
+	}
+
+	return key, nil
+}
+
+func (s *AvatarStorage) write(name string, img image.Image) error {
+	file, err := os.Create(filepath.Join(s.dir, name))
+	if err != nil {
+		return err
+	}
+
+	if err = png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (s *AvatarStorage) Delete(key string) {
+	if key == "" {
+		return
+	}
+	for _, size := range AvatarSizes {
+		os.Remove(filepath.Join(s.dir, s.fileName(key, size)))
+	}
+}
+
+func cropSquare(img image.Image) image.Image {
+	bounds := img.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	square := image.Rect(x, y, x+side, y+side)
+
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(square)
+	}
+	return img
+}
@@ -0,0 +1,67 @@
+package profile
+
+import (
+	"context"
+	"sync"
+	"task-manager-backend/internal/app/models/users"
+	"time"
+)
+
+const (
+	// localeTTL bounds how long an instance that did not serve the update
+	// keeps using a locale the user has changed.
+	localeTTL = time.Minute
+	// maxCachedLocales caps the cache. It is emptied when full, which is
+	// cheaper than evicting and only costs a lookup per user.
+	maxCachedLocales = 10000
+)
+
+type cachedLocale struct {
+	locale  string
+	expires time.Time
+}
+
+// localeCache keeps the saved locales of users, which error responses are
+// translated to, so that failing requests do not each load the user.
+type localeCache struct {
+	mu      sync.Mutex
+	locales map[users.ID]cachedLocale
+}
+
+func newLocaleCache() *localeCache {
+	return &localeCache{locales: make(map[users.ID]cachedLocale)}
+}
+
+func (c *localeCache) get(userID users.ID) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.locales[userID]
+	if !ok || time.Now().After(cached.expires) {
+		return "", false
+	}
+	return cached.locale, true
+}
+
+func (c *localeCache) put(userID users.ID, locale string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.locales) >= maxCachedLocales {
+		c.locales = make(map[users.ID]cachedLocale)
+	}
+	c.locales[userID] = cachedLocale{locale: locale, expires: time.Now().Add(localeTTL)}
+}
+
+// Locale returns the locale the user saved in their profile.
+func (s *Service) Locale(ctx context.Context, userID users.ID) (string, error) {
+	if locale, ok := s.locales.get(userID); ok {
+		return locale, nil
+	}
+
+	profile, err := s.Get(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return profile.Locale, nil
+}
@@ -0,0 +1,49 @@
+package profile
+
+import (
+	"context"
+	"task-manager-backend/internal/app/models/users"
+	"testing"
+)
+
+// memoryRepository counts how often a user is loaded.
+type memoryRepository struct {
+	Repository
+	user  users.User
+	loads int
+}
+
+func (r *memoryRepository) GetUserByUserID(context.Context, users.ID) (users.User, error) {
+	r.loads++
+	return r.user, nil
+}
+
+func (r *memoryRepository) UpdateProfile(_ context.Context, _ users.ID, update users.ProfileUpdate) error {
+	if update.Locale != nil {
+		r.user.Locale = *update.Locale
+	}
+	return nil
+}
+
+func TestLocaleIsCached(t *testing.T) {
+	ctx := context.Background()
+	repository := &memoryRepository{user: users.User{ID: 1, Locale: "ru"}}
+	s := &Service{repository: repository, avatars: &AvatarStorage{}, locales: newLocaleCache()}
+
+	for i := 0; i < 3; i++ {
+		if locale, err := s.Locale(ctx, 1); err != nil || locale != "ru" {
+			t.Fatalf("Locale: %q, %v, want ru", locale, err)
+		}
+	}
+	if repository.loads != 1 {
+		t.Fatalf("user loaded %d times, want once", repository.loads)
+	}
+
+	en := "en"
+	if _, err := s.Update(ctx, 1, users.ProfileUpdate{Locale: &en}); err != nil {
+		t.Fatal(err)
+	}
+	if locale, err := s.Locale(ctx, 1); err != nil || locale != "en" {
+		t.Fatalf("Locale after the update: %q, %v, want en", locale, err)
+	}
+}
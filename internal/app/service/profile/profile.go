@@ -0,0 +1,154 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/text/language"
+	"io"
+	"os"
+	"strings"
+	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/errs"
+	"task-manager-backend/internal/app/models/users"
+	"time"
+	_ "time/tzdata"
+)
+
+const (
+	maxDisplayNameLength = 64
+	maxBioLength         = 500
+
+	defaultAvatarsDir       = "avatars"
+	defaultAvatarsURLPrefix = "/avatars"
+)
+
+var (
+	InvalidData    = errs.New(errs.Invalid, errs.CodeInvalid, "Invalid data")
+	InvalidImage   = errs.New(errs.Invalid, "invalid_image", "Unsupported or corrupted image")
+	AvatarTooLarge = errs.New(errs.TooLarge, "avatar_too_large", "Avatar is too large")
+	UserNotFound   = errs.New(errs.NotFound, "user_not_found", "User not found")
+)
+
+type Repository interface {
+	GetUserByUserID(context.Context, users.ID) (users.User, error)
+	UpdateProfile(context.Context, users.ID, users.ProfileUpdate) error
+	SetAvatar(context.Context, users.ID, string) error
+}
+
+func NewService(cfg config.ServiceConfiguration, repository Repository) (*Service, error) {
+	avatars := &AvatarStorage{
+		dir:       cfg.Avatars.Dir,
+		urlPrefix: strings.TrimSuffix(cfg.Avatars.URLPrefix, "/"),
+	}
+	if avatars.dir == "" {
+		avatars.dir = defaultAvatarsDir
+	}
+	if avatars.urlPrefix == "" {
+		avatars.urlPrefix = defaultAvatarsURLPrefix
+	}
+	if err := os.MkdirAll(avatars.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("profile.NewService: cant create avatars dir: %w", err)
+	}
+
+	return &Service{
+		repository: repository,
+		avatars:    avatars,
+		locales:    newLocaleCache(),
+	}, nil
+}
+
+type Service struct {
+	repository Repository
+	avatars    *AvatarStorage
+	locales    *localeCache
+}
+
+// AvatarsDir and AvatarsURLPrefix tell the router where to serve avatars from.
+func (s *Service) AvatarsDir() string {
+	return s.avatars.dir
+}
+
+func (s *Service) AvatarsURLPrefix() string {
+	return s.avatars.urlPrefix
+}
+
+func (s *Service) Get(ctx context.Context, userID users.ID) (users.Profile, error) {
+	user, err := s.repository.GetUserByUserID(ctx, userID)
+	if err != nil {
+		return users.Profile{}, err
+	}
+	if user.ID != userID {
+		return users.Profile{}, UserNotFound
+	}
+
+	profile := user.Profile()
+	profile.Avatars = s.avatars.URLs(user.Avatar)
+	s.locales.put(userID, profile.Locale)
+	return profile, nil
+}
+
+func (s *Service) Update(ctx context.Context, userID users.ID, update users.ProfileUpdate) (users.Profile, error) {
+	if err := validateUpdate(&update); err != nil {
+		return users.Profile{}, err
+	}
+
+	if err := s.repository.UpdateProfile(ctx, userID, update); err != nil {
+		return users.Profile{}, err
+	}
+
+	return s.Get(ctx, userID)
+}
+
+func (s *Service) UploadAvatar(ctx context.Context, userID users.ID, image io.Reader) (users.Profile, error) {
+	user, err := s.repository.GetUserByUserID(ctx, userID)
+	if err != nil {
+		return users.Profile{}, err
+	}
+	if user.ID != userID {
+		return users.Profile{}, UserNotFound
+	}
+
+	key, err := s.avatars.Save(image)
+	if err != nil {
+		return users.Profile{}, err
+	}
+
+	if err = s.repository.SetAvatar(ctx, userID, key); err != nil {
+		s.avatars.Delete(key)
+		return users.Profile{}, err
+	}
+	s.avatars.Delete(user.Avatar)
+
+	return s.Get(ctx, userID)
+}
+
+func validateUpdate(update *users.ProfileUpdate) error {
+	if update.DisplayName != nil {
+		name := strings.TrimSpace(*update.DisplayName)
+		if len([]rune(name)) > maxDisplayNameLength {
+			return InvalidData
+		}
+		update.DisplayName = &name
+	}
+
+	if update.Bio != nil && len([]rune(*update.Bio)) > maxBioLength {
+		return InvalidData
+	}
+
+	if update.Timezone != nil {
+		if _, err := time.LoadLocation(*update.Timezone); err != nil || *update.Timezone == "" || *update.Timezone == "Local" {
+			return InvalidData
+		}
+	}
+
+	if update.Locale != nil {
+		tag, err := language.Parse(*update.Locale)
+		if err != nil {
+			return InvalidData
+		}
+		locale := tag.String()
+		update.Locale = &locale
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package realtime
+
+import (
+	"context"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"sync"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/models/workspaces"
+	"task-manager-backend/internal/app/worker"
+	"time"
+)
+
+const (
+	consumer        = "realtime"
+	readBatch       = 100
+	readBlock       = 5 * time.Second
+	retryDelay      = time.Second
+	noBlock         = -1
+	replayLimit     = 1000
+	subscriberQueue = 64
+)
+
+// StreamedEvents are the events forwarded to connected clients.
+var StreamedEvents = []events.Type{
+	events.TaskCreated,
+	events.TaskUpdated,
+	events.TaskMoved,
+	events.TaskAssigned,
+	events.TaskStatusChanged,
+	events.CommentCreated,
+	events.BoardUpdated,
+}
+
+type Repository interface {
+	AppendRealtimeEvent(context.Context, events.Event) (string, error)
+	LastRealtimeEventID(context.Context) (string, error)
+	ReadRealtimeEvents(context.Context, string, int64, time.Duration) ([]events.Record, error)
+
+	GetWorkspacesByUserID(context.Context, users.ID) ([]workspaces.Workspace, error)
+}
+
+func NewService(repository Repository, logger *zap.Logger) *Service {
+	return &Service{
+		repository:  repository,
+		logger:      logger,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Service fans events out to the clients connected to this instance. Events
+// travel through a Redis stream, so every instance sees events published by
+// any other, and the stream doubles as history for resuming after a
+// reconnect.
+type Service struct {
+	repository Repository
+	logger     *zap.Logger
+
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+}
+
+type Subscription struct {
+	C          chan events.Record
+	workspaces map[workspaces.ID]struct{}
+	service    *Service
+	closeOnce  sync.Once
+}
+
+func (s *Subscription) Close() {
+	s.service.unsubscribe(s)
+}
+
+// RegisterHandlers forwards streamed events from the bus to Redis.
+func RegisterHandlers(bus *events.Bus, service *Service, outbox events.OutboxRepository) {
+	for _, eventType := range StreamedEvents {
+		bus.Subscribe(eventType, events.Once(consumer, outbox, service.append))
+	}
+}
+
+func (s *Service) append(ctx context.Context, event events.Event) error {
+	if event.WorkspaceID == 0 {
+		return nil
+	}
+	_, err := s.repository.AppendRealtimeEvent(ctx, event)
+	return err
+}
+
+// Hook runs the loop reading the shared stream for the lifetime of the app.
+func Hook(lifecycle fx.Lifecycle, service *Service) {
+	worker.Hook(lifecycle, service.readLoop)
+}
+
+func (s *Service) readLoop(stop <-chan struct{}) {
+	ctx := context.Background()
+	last := ""
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		var err error
+		if last == "" {
+			last, err = s.repository.LastRealtimeEventID(ctx)
+		} else {
+			var records []events.Record
+			records, err = s.repository.ReadRealtimeEvents(ctx, last, readBatch, readBlock)
+			for _, record := range records {
+				s.broadcast(record)
+				last = record.ID
+			}
+		}
+
+		if err != nil {
+			s.logger.Error("Realtime: Cant read events", zap.Error(err))
+			time.Sleep(retryDelay)
+		}
+	}
+}
+
+func (s *Service) broadcast(record events.Record) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for sub := range s.subscribers {
+		if _, ok := sub.workspaces[record.Event.WorkspaceID]; !ok {
+			continue
+		}
+
+		select {
+		case sub.C <- record:
+		default:
+			// The client cannot keep up; it is disconnected and resumes
+			// from its last event ID after reconnecting.
+			go sub.Close()
+		}
+	}
+}
+
+// Subscribe streams events of the user's workspaces. With lastEventID set,
+// missed events still kept in the stream are delivered first.
+func (s *Service) Subscribe(ctx context.Context, userID users.ID, lastEventID string) (*Subscription, []events.Record, error) {
+	list, err := s.repository.GetWorkspacesByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sub := &Subscription{
+		C:          make(chan events.Record, subscriberQueue),
+		workspaces: make(map[workspaces.ID]struct{}, len(list)),
+		service:    s,
+	}
+	for _, workspace := range list {
+		sub.workspaces[workspace.ID] = struct{}{}
+	}
+
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+
+	if lastEventID == "" {
+		return sub, nil, nil
+	}
+
+	missed, err := s.repository.ReadRealtimeEvents(ctx, lastEventID, replayLimit, noBlock)
+	if err != nil {
+		sub.Close()
+		return nil, nil, err
+	}
+
+	replay := make([]events.Record, 0, len(missed))
+	for _, record := range missed {
+		if _, ok := sub.workspaces[record.Event.WorkspaceID]; ok {
+			replay = append(replay, record)
+		}
+	}
+	return sub, replay, nil
+}
+
+func (s *Service) unsubscribe(sub *Subscription) {
+	sub.closeOnce.Do(func() {
+		s.mu.Lock()
+		delete(s.subscribers, sub)
+		s.mu.Unlock()
+		close(sub.C)
+	})
+}
+
+// IsAfter reports whether stream ID a was assigned after b. IDs have the
+// form <milliseconds>-<sequence>.
+func IsAfter(a, b string) bool {
+	if b == "" {
+		return true
+	}
+	aMs, aSeq := splitID(a)
+	bMs, bSeq := splitID(b)
+	if aMs != bMs {
+		return aMs > bMs
+	}
+	return aSeq > bSeq
+}
+
+func splitID(id string) (uint64, uint64) {
+	ms, seq, _ := strings.Cut(id, "-")
+	msValue, _ := strconv.ParseUint(ms, 10, 64)
+	seqValue, _ := strconv.ParseUint(seq, 10, 64)
+	return msValue, seqValue
+}
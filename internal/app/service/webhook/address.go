@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const dialTimeout = 5 * time.Second
+
+// blockedNetworks are not covered by the net.IP predicates used in blocked
+// but are just as unreachable from the internet.
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // "this network"
+	"100.64.0.0/10", // carrier-grade NAT
+)
+
+// addressGuard keeps webhooks from reaching the service's own network:
+// loopback, private, link-local and unspecified addresses are refused unless
+// they are in an allowed network.
+type addressGuard struct {
+	allowed []*net.IPNet
+	lookup  func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+func newAddressGuard(allowedNetworks []string) (*addressGuard, error) {
+	allowed, err := parseCIDRs(allowedNetworks)
+	if err != nil {
+		return nil, err
+	}
+	return &addressGuard{
+		allowed: allowed,
+		lookup:  net.DefaultResolver.LookupIPAddr,
+	}, nil
+}
+
+func (g *addressGuard) permitted(ip net.IP) bool {
+	if contains(g.allowed, ip) {
+		return true
+	}
+	return !blocked(ip)
+}
+
+func blocked(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() ||
+		contains(blockedNetworks, ip)
+}
+
+// validateURL tells whether the URL can take deliveries. Every address the
+// host resolves to must be permitted. This only gives early feedback: DNS
+// may answer differently later, so the dialer checks again on every
+// delivery.
+func (g *addressGuard) validateURL(ctx context.Context, rawURL string) bool {
+	if len(rawURL) > maxURLLength {
+		return false
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return false
+	}
+
+	addrs, err := g.lookup(ctx, parsed.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return false
+	}
+	for _, addr := range addrs {
+		if !g.permitted(addr.IP) {
+			return false
+		}
+	}
+	return true
+}
+
+// control runs after the address is resolved and before connecting, so it
+// sees the address actually dialed.
+func (g *addressGuard) control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !g.permitted(ip) {
+		return fmt.Errorf("webhook address %s is not allowed", host)
+	}
+	return nil
+}
+
+// client sends deliveries. It connects directly rather than through a
+// proxy from the environment, whose address would be the one checked, and
+// does not follow redirects, which could lead anywhere.
+func (g *addressGuard) client() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: dialTimeout,
+		Control: g.control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   requestTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func contains(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("webhook.NewService: %w", err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks, err := parseCIDRs(cidrs)
+	if err != nil {
+		panic(err)
+	}
+	return networks
+}
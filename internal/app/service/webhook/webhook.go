@@ -0,0 +1,239 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"net/http"
+	"task-manager-backend/internal/app/config"
+	"task-manager-backend/internal/app/errs"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/webhooks"
+	"task-manager-backend/internal/app/models/workspaces"
+	"time"
+)
+
+const (
+	consumer     = "webhook"
+	DefaultLimit = 20
+	MaxLimit     = 100
+	maxURLLength = 2048
+)
+
+var (
+	InvalidData = errs.New(errs.Invalid, errs.CodeInvalid, "Invalid data")
+	NotAllowed  = errs.New(errs.Forbidden, "not_allowed", "Not enough rights")
+	NotFound    = errs.New(errs.NotFound, "webhook_not_found", "Webhook not found")
+	Disabled    = errs.New(errs.Conflict, "webhook_disabled", "Webhook is disabled")
+)
+
+// DeliveredEvents are the event types webhooks can subscribe to.
+var DeliveredEvents = []events.Type{
+	events.TaskCreated,
+	events.TaskUpdated,
+	events.CommentCreated,
+	events.MemberAdded,
+}
+
+type Repository interface {
+	CreateWebhook(context.Context, webhooks.Webhook) error
+	GetWebhooks(context.Context, workspaces.ID) ([]webhooks.Webhook, error)
+	GetWebhook(context.Context, workspaces.ID, webhooks.ID) (webhooks.Webhook, error)
+	DeleteWebhook(context.Context, workspaces.ID, webhooks.ID) (bool, error)
+	SetWebhookActive(context.Context, workspaces.ID, webhooks.ID, bool) error
+	RecordWebhookFailure(context.Context, workspaces.ID, webhooks.ID) (int, error)
+	ResetWebhookFailures(context.Context, workspaces.ID, webhooks.ID) error
+
+	CreateWebhookDelivery(context.Context, webhooks.Delivery) error
+	GetWebhookDeliveries(context.Context, workspaces.ID, webhooks.ID, uint64, uint64) ([]webhooks.Delivery, error)
+	GetWebhookDelivery(context.Context, workspaces.ID, webhooks.ID, webhooks.DeliveryID) (webhooks.Delivery, error)
+	ClaimWebhookDeliveries(context.Context, uint64, time.Duration) ([]webhooks.Delivery, error)
+	UpdateWebhookDelivery(context.Context, webhooks.Delivery) error
+}
+
+func NewService(cfg config.ServiceConfiguration, repository Repository, logger *zap.Logger) (*Service, error) {
+	guard, err := newAddressGuard(cfg.Webhooks.AllowedNetworks)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		repository: repository,
+		guard:      guard,
+		client:     guard.client(),
+		logger:     logger,
+	}, nil
+}
+
+// Service manages the webhooks of workspaces and delivers events to them.
+// Deliveries are queued in Postgres and sent by a background worker, so a
+// slow or failing receiver never holds up the request that caused the event.
+type Service struct {
+	repository Repository
+	guard      *addressGuard
+	client     *http.Client
+	logger     *zap.Logger
+}
+
+// RegisterHandlers queues a delivery for every published event webhooks can
+// subscribe to.
+func RegisterHandlers(bus *events.Bus, service *Service, outbox events.OutboxRepository) {
+	for _, eventType := range DeliveredEvents {
+		bus.Subscribe(eventType, events.Once(consumer, outbox, service.enqueue))
+	}
+}
+
+func (s *Service) enqueue(ctx context.Context, event events.Event) error {
+	if event.WorkspaceID == 0 {
+		return nil
+	}
+
+	list, err := s.repository.GetWebhooks(ctx, event.WorkspaceID)
+	if err != nil {
+		return err
+	}
+
+	var payload json.RawMessage
+	for _, webhook := range list {
+		if !webhook.Active || !webhook.Subscribed(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return err
+			}
+		}
+		if err := s.repository.CreateWebhookDelivery(ctx, webhooks.NewDelivery(webhook, event.Type, payload)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) Create(ctx context.Context, actor workspaces.Member, rawURL string, eventTypes []string) (webhooks.Webhook, error) {
+	if !actor.Role.CanManageMembers() {
+		return webhooks.Webhook{}, NotAllowed
+	}
+	if !s.guard.validateURL(ctx, rawURL) || !validateEventTypes(eventTypes) {
+		return webhooks.Webhook{}, InvalidData
+	}
+
+	webhook, err := webhooks.NewWebhook(actor.WorkspaceID, rawURL, eventTypes)
+	if err != nil {
+		return webhooks.Webhook{}, err
+	}
+	return webhook, s.repository.CreateWebhook(ctx, webhook)
+}
+
+// List returns the workspace's webhooks. Secrets are only shown once, when a
+// webhook is created.
+func (s *Service) List(ctx context.Context, actor workspaces.Member) ([]webhooks.Webhook, error) {
+	if !actor.Role.CanManageMembers() {
+		return nil, NotAllowed
+	}
+
+	list, err := s.repository.GetWebhooks(ctx, actor.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Secret = ""
+	}
+	return list, nil
+}
+
+func (s *Service) Delete(ctx context.Context, actor workspaces.Member, webhookID webhooks.ID) error {
+	if !actor.Role.CanManageMembers() {
+		return NotAllowed
+	}
+
+	deleted, err := s.repository.DeleteWebhook(ctx, actor.WorkspaceID, webhookID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return NotFound
+	}
+	return nil
+}
+
+// Enable turns a webhook back on, e.g. after it was disabled for failing.
+func (s *Service) Enable(ctx context.Context, actor workspaces.Member, webhookID webhooks.ID) error {
+	if _, err := s.webhook(ctx, actor, webhookID); err != nil {
+		return err
+	}
+	return s.repository.SetWebhookActive(ctx, actor.WorkspaceID, webhookID, true)
+}
+
+func (s *Service) Deliveries(ctx context.Context, actor workspaces.Member, webhookID webhooks.ID, limit, offset uint64) ([]webhooks.Delivery, error) {
+	if _, err := s.webhook(ctx, actor, webhookID); err != nil {
+		return nil, err
+	}
+
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	return s.repository.GetWebhookDeliveries(ctx, actor.WorkspaceID, webhookID, limit, offset)
+}
+
+// Replay queues the payload of a past delivery again. The original delivery
+// stays in the log as it was.
+func (s *Service) Replay(ctx context.Context, actor workspaces.Member, webhookID webhooks.ID, deliveryID webhooks.DeliveryID) (webhooks.Delivery, error) {
+	webhook, err := s.webhook(ctx, actor, webhookID)
+	if err != nil {
+		return webhooks.Delivery{}, err
+	}
+	if !webhook.Active {
+		return webhooks.Delivery{}, Disabled
+	}
+
+	original, err := s.repository.GetWebhookDelivery(ctx, actor.WorkspaceID, webhookID, deliveryID)
+	if err != nil {
+		return webhooks.Delivery{}, err
+	}
+	if original.ID != deliveryID {
+		return webhooks.Delivery{}, NotFound
+	}
+
+	delivery := webhooks.NewDelivery(webhook, original.EventType, original.Payload)
+	return delivery, s.repository.CreateWebhookDelivery(ctx, delivery)
+}
+
+func (s *Service) webhook(ctx context.Context, actor workspaces.Member, webhookID webhooks.ID) (webhooks.Webhook, error) {
+	if !actor.Role.CanManageMembers() {
+		return webhooks.Webhook{}, NotAllowed
+	}
+
+	webhook, err := s.repository.GetWebhook(ctx, actor.WorkspaceID, webhookID)
+	if err != nil {
+		return webhooks.Webhook{}, err
+	}
+	if webhook.ID != webhookID {
+		return webhooks.Webhook{}, NotFound
+	}
+	return webhook, nil
+}
+
+func validateEventTypes(eventTypes []string) bool {
+	if len(eventTypes) == 0 {
+		return false
+	}
+	for _, eventType := range eventTypes {
+		if !isDelivered(events.Type(eventType)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDelivered(eventType events.Type) bool {
+	for _, delivered := range DeliveredEvents {
+		if delivered == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,320 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/webhooks"
+	"task-manager-backend/internal/app/models/workspaces"
+	"testing"
+	"time"
+)
+
+// memoryRepository keeps webhooks and deliveries in maps. Methods the tests
+// dont reach are left to the embedded nil interface.
+type memoryRepository struct {
+	Repository
+	webhooks   map[webhooks.ID]webhooks.Webhook
+	deliveries map[webhooks.DeliveryID]webhooks.Delivery
+}
+
+func newMemoryRepository(list ...webhooks.Webhook) *memoryRepository {
+	r := &memoryRepository{
+		webhooks:   make(map[webhooks.ID]webhooks.Webhook),
+		deliveries: make(map[webhooks.DeliveryID]webhooks.Delivery),
+	}
+	for _, webhook := range list {
+		r.webhooks[webhook.ID] = webhook
+	}
+	return r
+}
+
+func (r *memoryRepository) GetWebhook(_ context.Context, workspaceID workspaces.ID, webhookID webhooks.ID) (webhooks.Webhook, error) {
+	webhook := r.webhooks[webhookID]
+	if webhook.WorkspaceID != workspaceID {
+		return webhooks.Webhook{}, nil
+	}
+	return webhook, nil
+}
+
+func (r *memoryRepository) SetWebhookActive(_ context.Context, _ workspaces.ID, webhookID webhooks.ID, active bool) error {
+	webhook := r.webhooks[webhookID]
+	webhook.Active = active
+	r.webhooks[webhookID] = webhook
+	return nil
+}
+
+func (r *memoryRepository) RecordWebhookFailure(_ context.Context, _ workspaces.ID, webhookID webhooks.ID) (int, error) {
+	webhook := r.webhooks[webhookID]
+	webhook.Failures++
+	r.webhooks[webhookID] = webhook
+	return webhook.Failures, nil
+}
+
+func (r *memoryRepository) ResetWebhookFailures(_ context.Context, _ workspaces.ID, webhookID webhooks.ID) error {
+	webhook := r.webhooks[webhookID]
+	webhook.Failures = 0
+	r.webhooks[webhookID] = webhook
+	return nil
+}
+
+func (r *memoryRepository) UpdateWebhookDelivery(_ context.Context, delivery webhooks.Delivery) error {
+	r.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+// newTestService delivers to the test server, which listens on loopback.
+func newTestService(t *testing.T, repository Repository) *Service {
+	t.Helper()
+	guard, err := newAddressGuard([]string{"127.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Service{repository: repository, guard: guard, client: guard.client(), logger: zap.NewNop()}
+}
+
+func newTestWebhook(t *testing.T, url string) webhooks.Webhook {
+	t.Helper()
+	webhook, err := webhooks.NewWebhook(1, url, []string{string(events.TaskCreated)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return webhook
+}
+
+// claimed returns the delivery as ClaimWebhookDeliveries would: with the
+// attempt about to be made counted.
+func claimed(webhook webhooks.Webhook, attempts int) webhooks.Delivery {
+	delivery := webhooks.NewDelivery(webhook, events.TaskCreated, []byte(`{"id":1}`))
+	delivery.Attempts = attempts
+	return delivery
+}
+
+func TestSign(t *testing.T) {
+	got := Sign("secret", 1700000000, []byte(`{"id":1}`))
+	want := "sha256=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11"
+	if got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	resolved := map[string][]string{
+		"hooks.example.com": {"93.184.216.34"},
+		"internal.example":  {"10.1.2.3"},
+		"mixed.example":     {"93.184.216.34", "127.0.0.1"},
+		"partner.internal":  {"10.20.0.5"},
+	}
+	guard, err := newAddressGuard([]string{"10.20.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	guard.lookup = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		if ip := net.ParseIP(host); ip != nil {
+			return []net.IPAddr{{IP: ip}}, nil
+		}
+		addrs := make([]net.IPAddr, 0)
+		for _, ip := range resolved[host] {
+			addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+		}
+		if len(addrs) == 0 {
+			return nil, errors.New("no such host")
+		}
+		return addrs, nil
+	}
+
+	for _, tt := range []struct {
+		url  string
+		want bool
+	}{
+		{"https://hooks.example.com/task", true},
+		{"http://hooks.example.com:8080/task", true},
+		{"https://93.184.216.34/task", true},
+		{"https://partner.internal/task", true},
+		{"ftp://hooks.example.com/task", false},
+		{"https:///task", false},
+		{"hooks.example.com/task", false},
+		{"https://hooks.example.com/" + strings.Repeat("a", maxURLLength), false},
+		{"https://unknown.example/task", false},
+		{"http://127.0.0.1/task", false},
+		{"http://[::1]/task", false},
+		{"http://[::ffff:127.0.0.1]/task", false},
+		{"http://0.0.0.0/task", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://192.168.1.1/task", false},
+		{"http://100.64.0.1/task", false},
+		{"https://internal.example/task", false},
+		{"https://mixed.example/task", false},
+	} {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := guard.validateURL(context.Background(), tt.url); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendRefusesBlockedAddress(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	guard, err := newAddressGuard(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{guard: guard, client: guard.client()}
+	webhook := newTestWebhook(t, server.URL)
+
+	if _, err := s.send(context.Background(), webhook, claimed(webhook, 1)); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("got %v, want the address refused", err)
+	}
+	if requests != 0 {
+		t.Fatal("request reached a loopback address")
+	}
+}
+
+func TestSendSignsDelivery(t *testing.T) {
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+	}))
+	defer server.Close()
+
+	s := newTestService(t, newMemoryRepository())
+	webhook := newTestWebhook(t, server.URL)
+	delivery := claimed(webhook, 1)
+
+	status, err := s.send(context.Background(), webhook, delivery)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("send: status %d, error %v", status, err)
+	}
+
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header: %v", err)
+	}
+	if got, want := header.Get(HeaderSignature), Sign(webhook.Secret, timestamp, body); got != want {
+		t.Errorf("signature %s, want %s", got, want)
+	}
+	if got := header.Get(HeaderEvent); got != string(events.TaskCreated) {
+		t.Errorf("event header %q, want %q", got, events.TaskCreated)
+	}
+	if got, want := header.Get(HeaderDelivery), strconv.FormatUint(uint64(delivery.ID), 10); got != want {
+		t.Errorf("delivery header %q, want %q", got, want)
+	}
+}
+
+func TestSendDoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/elsewhere" {
+			followed = true
+			return
+		}
+		http.Redirect(w, r, "/elsewhere", http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	s := newTestService(t, newMemoryRepository())
+	webhook := newTestWebhook(t, server.URL)
+
+	status, err := s.send(context.Background(), webhook, claimed(webhook, 1))
+	if err == nil || status != http.StatusTemporaryRedirect {
+		t.Fatalf("got status %d, error %v, want the redirect as a failure", status, err)
+	}
+	if followed {
+		t.Fatal("redirect was followed")
+	}
+}
+
+func TestAttempt(t *testing.T) {
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	webhook := newTestWebhook(t, server.URL)
+	repository := newMemoryRepository(webhook)
+	s := newTestService(t, repository)
+
+	delivery := claimed(webhook, 1)
+	if err := s.attempt(ctx, delivery); err != nil {
+		t.Fatal(err)
+	}
+	retried := repository.deliveries[delivery.ID]
+	if retried.Status != webhooks.Pending || retried.ResponseStatus != status || retried.LastError == "" {
+		t.Fatalf("failed attempt recorded as %+v, want pending with the error", retried)
+	}
+	if got := retried.NextAttemptAt.Sub(retried.UpdatedAt); got != backoffBase {
+		t.Errorf("retried after %s, want %s", got, backoffBase)
+	}
+
+	for i := 1; i <= DisableAfter; i++ {
+		delivery := claimed(webhook, MaxAttempts)
+		if err := s.attempt(ctx, delivery); err != nil {
+			t.Fatal(err)
+		}
+		if got := repository.deliveries[delivery.ID].Status; got != webhooks.Failed {
+			t.Fatalf("last attempt left the delivery %s, want %s", got, webhooks.Failed)
+		}
+		if got := repository.webhooks[webhook.ID].Failures; got != i {
+			t.Fatalf("webhook has %d failures, want %d", got, i)
+		}
+	}
+	if repository.webhooks[webhook.ID].Active {
+		t.Fatalf("webhook is active after %d failed deliveries", DisableAfter)
+	}
+
+	// Deliveries of a disabled webhook are given up on without sending.
+	delivery = claimed(webhook, 1)
+	if err := s.attempt(ctx, delivery); err != nil {
+		t.Fatal(err)
+	}
+	if got := repository.deliveries[delivery.ID]; got.Status != webhooks.Failed || got.ResponseStatus != 0 {
+		t.Fatalf("delivery to a disabled webhook recorded as %+v", got)
+	}
+
+	repository.SetWebhookActive(ctx, webhook.WorkspaceID, webhook.ID, true)
+	status = http.StatusNoContent
+	delivery = claimed(webhook, 2)
+	if err := s.attempt(ctx, delivery); err != nil {
+		t.Fatal(err)
+	}
+	if got := repository.deliveries[delivery.ID].Status; got != webhooks.Succeeded {
+		t.Fatalf("successful attempt left the delivery %s", got)
+	}
+	if got := repository.webhooks[webhook.ID].Failures; got != 0 {
+		t.Fatalf("webhook still has %d failures after a success", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for _, tt := range []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, backoffBase},
+		{2, 2 * backoffBase},
+		{4, 8 * backoffBase},
+		{MaxAttempts, 128 * backoffBase},
+		{100, backoffMax},
+	} {
+		if got := retry.Delay(tt.attempts); got != tt.want {
+			t.Errorf("retry.Delay(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+	"task-manager-backend/internal/app/models/webhooks"
+	"task-manager-backend/internal/app/worker"
+	"time"
+)
+
+// Headers sent with every delivery. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the webhook secret, so receivers can both
+// authenticate the request and reject replays of old ones.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+	signaturePrefix = "sha256="
+)
+
+const (
+	pollInterval   = time.Second
+	claimBatch     = 20
+	requestTimeout = 10 * time.Second
+	// claimLease outlasts sending a whole batch, so no other worker claims a
+	// delivery again while it is still waiting its turn.
+	claimLease     = claimBatch*requestTimeout + time.Minute
+	backoffBase    = 30 * time.Second
+	backoffMax     = 6 * time.Hour
+	maxErrorLength = 500
+
+	// MaxAttempts is how many times a delivery is tried before it is given
+	// up on.
+	MaxAttempts = 8
+	// DisableAfter is how many deliveries in a row may be given up on before
+	// the webhook is disabled.
+	DisableAfter = 3
+)
+
+var retry = worker.Backoff{Base: backoffBase, Max: backoffMax}
+
+// Sign returns the value of the signature header for the body.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Hook runs the delivery worker for the lifetime of the app.
+func Hook(lifecycle fx.Lifecycle, service *Service) {
+	worker.Hook(lifecycle, service.work)
+}
+
+func (s *Service) work(stop <-chan struct{}) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.deliverDue(context.Background())
+		}
+	}
+}
+
+func (s *Service) deliverDue(ctx context.Context) {
+	for {
+		claimed, err := s.repository.ClaimWebhookDeliveries(ctx, claimBatch, claimLease)
+		if err != nil {
+			s.logger.Error("Webhooks: Cant claim deliveries", zap.Error(err))
+			return
+		}
+
+		for _, delivery := range claimed {
+			if err := s.attempt(ctx, delivery); err != nil {
+				s.logger.Error("Webhooks: Cant record delivery", zap.Uint64("delivery_id", uint64(delivery.ID)), zap.Error(err))
+			}
+		}
+
+		if len(claimed) < claimBatch {
+			return
+		}
+	}
+}
+
+// attempt sends the delivery once and records the outcome. Failed attempts
+// are retried with exponential backoff until MaxAttempts is reached.
+func (s *Service) attempt(ctx context.Context, delivery webhooks.Delivery) error {
+	webhook, err := s.repository.GetWebhook(ctx, delivery.WorkspaceID, delivery.WebhookID)
+	if err != nil {
+		return err
+	}
+
+	delivery.UpdatedAt = time.Now()
+	if webhook.ID != delivery.WebhookID || !webhook.Active {
+		delivery.Status = webhooks.Failed
+		delivery.LastError = Disabled.Error()
+		return s.repository.UpdateWebhookDelivery(ctx, delivery)
+	}
+
+	delivery.ResponseStatus, err = s.send(ctx, webhook, delivery)
+	if err == nil {
+		delivery.Status = webhooks.Succeeded
+		delivery.LastError = ""
+		if err := s.repository.UpdateWebhookDelivery(ctx, delivery); err != nil {
+			return err
+		}
+		return s.repository.ResetWebhookFailures(ctx, webhook.WorkspaceID, webhook.ID)
+	}
+
+	delivery.LastError = worker.Truncate(err.Error(), maxErrorLength)
+	if delivery.Attempts < MaxAttempts {
+		delivery.NextAttemptAt = delivery.UpdatedAt.Add(retry.Delay(delivery.Attempts))
+		return s.repository.UpdateWebhookDelivery(ctx, delivery)
+	}
+
+	delivery.Status = webhooks.Failed
+	if err := s.repository.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		return err
+	}
+
+	failures, err := s.repository.RecordWebhookFailure(ctx, webhook.WorkspaceID, webhook.ID)
+	if err != nil {
+		return err
+	}
+	if failures < DisableAfter {
+		return nil
+	}
+
+	s.logger.Warn("Webhooks: Disabling webhook after failed deliveries", zap.Uint64("webhook_id", uint64(webhook.ID)), zap.Int("failures", failures))
+	return s.repository.SetWebhookActive(ctx, webhook.WorkspaceID, webhook.ID, false)
+}
+
+// send posts the payload and returns the response status. Any status outside
+// 2xx counts as a failure.
+func (s *Service) send(ctx context.Context, webhook webhooks.Webhook, delivery webhooks.Delivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
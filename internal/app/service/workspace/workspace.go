@@ -0,0 +1,109 @@
+package workspace
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"task-manager-backend/internal/app/errs"
+	"task-manager-backend/internal/app/events"
+	"task-manager-backend/internal/app/models/users"
+	"task-manager-backend/internal/app/models/workspaces"
+)
+
+const maxNameLength = 100
+
+var (
+	InvalidData   = errs.New(errs.Invalid, errs.CodeInvalid, "Invalid data")
+	NotMember     = errs.New(errs.Forbidden, "not_workspace_member", "You are not a member of this workspace")
+	NotAllowed    = errs.New(errs.Forbidden, "not_allowed", "Not enough rights")
+	UserNotFound  = errs.New(errs.NotFound, "user_not_found", "User not found")
+	AlreadyMember = errs.New(errs.Conflict, "already_member", "User is already a member of this workspace")
+)
+
+type Repository interface {
+	CreateWorkspace(context.Context, workspaces.Workspace) error
+	GetWorkspacesByUserID(context.Context, users.ID) ([]workspaces.Workspace, error)
+	GetWorkspaceMember(context.Context, workspaces.ID, users.ID) (workspaces.Member, error)
+	GetWorkspaceMembers(context.Context, workspaces.ID) ([]workspaces.Member, error)
+	AddWorkspaceMember(context.Context, workspaces.Member, ...events.Event) error
+
+	GetUserByEmail(context.Context, users.Email) (users.User, error)
+}
+
+func NewService(repository Repository) *Service {
+	return &Service{
+		repository: repository,
+	}
+}
+
+type Service struct {
+	repository Repository
+}
+
+func (s *Service) Create(ctx context.Context, ownerID users.ID, name string) (workspaces.Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxNameLength {
+		return workspaces.Workspace{}, InvalidData
+	}
+
+	workspace := workspaces.NewWorkspace(name, ownerID)
+	return workspace, s.repository.CreateWorkspace(ctx, workspace)
+}
+
+func (s *Service) List(ctx context.Context, userID users.ID) ([]workspaces.Workspace, error) {
+	return s.repository.GetWorkspacesByUserID(ctx, userID)
+}
+
+// Member resolves the caller's membership in the workspace and is what every
+// workspace-scoped request is authorised with.
+func (s *Service) Member(ctx context.Context, workspaceID workspaces.ID, userID users.ID) (workspaces.Member, error) {
+	member, err := s.repository.GetWorkspaceMember(ctx, workspaceID, userID)
+	if err != nil {
+		return workspaces.Member{}, err
+	}
+	if member.UserID != userID {
+		return workspaces.Member{}, NotMember
+	}
+	return member, nil
+}
+
+func (s *Service) Members(ctx context.Context, actor workspaces.Member) ([]workspaces.Member, error) {
+	return s.repository.GetWorkspaceMembers(ctx, actor.WorkspaceID)
+}
+
+func (s *Service) AddMember(ctx context.Context, actor workspaces.Member, email users.Email, role workspaces.Role) (workspaces.Member, error) {
+	if !users.ValidateEmail(email) || !workspaces.ValidateRole(role) || role == workspaces.RoleOwner {
+		return workspaces.Member{}, InvalidData
+	}
+	if !actor.Role.CanManageMembers() {
+		return workspaces.Member{}, NotAllowed
+	}
+
+	user, err := s.repository.GetUserByEmail(ctx, email)
+	if err != nil {
+		return workspaces.Member{}, err
+	}
+	if user.Email != email {
+		return workspaces.Member{}, UserNotFound
+	}
+
+	existing, err := s.repository.GetWorkspaceMember(ctx, actor.WorkspaceID, user.ID)
+	if err != nil {
+		return workspaces.Member{}, err
+	}
+	if existing.UserID == user.ID {
+		return workspaces.Member{}, AlreadyMember
+	}
+
+	member := workspaces.Member{
+		WorkspaceID: actor.WorkspaceID,
+		UserID:      user.ID,
+		Role:        role,
+	}
+	event := events.NewEvent(events.MemberAdded, actor.UserID, []users.ID{user.ID}, map[string]string{
+		events.PayloadUser: strconv.FormatUint(uint64(user.ID), 10),
+		events.PayloadRole: string(role),
+	})
+	event.WorkspaceID = actor.WorkspaceID
+	return member, s.repository.AddWorkspaceMember(ctx, member, event)
+}
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+const sqlTracerName = "task-manager-backend/internal/app/tracing/sql"
+
+// WrapConnector traces the queries and statements run on the connections of
+// the connector as children of the span in their context. Only the SQL text
+// is recorded, never the arguments.
+func WrapConnector(connector driver.Connector, system string) driver.Connector {
+	return tracedConnector{Connector: connector, system: system}
+}
+
+type tracedConnector struct {
+	driver.Connector
+	system string
+}
+
+func (c tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: conn, system: c.system}, nil
+}
+
+// tracedConn implements the optional interfaces the wrapped connection
+// implements, falling back to database/sql defaults otherwise.
+type tracedConn struct {
+	driver.Conn
+	system string
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := c.start(ctx, query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	End(span, skipped(err))
+	return rows, err
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := c.start(ctx, query)
+	result, err := execer.ExecContext(ctx, query, args)
+	End(span, skipped(err))
+	return result, err
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	operation = strings.ToUpper(operation)
+
+	return otel.Tracer(sqlTracerName).Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String(string(semconv.DBSystemKey), c.system),
+			semconv.DBOperation(operation),
+			semconv.DBStatement(query),
+		))
+}
+
+// skipped hides driver.ErrSkip, which only makes database/sql take another
+// path and is not a failure.
+func skipped(err error) error {
+	if err == driver.ErrSkip {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"go.uber.org/fx"
+	"time"
+	"unicode/utf8"
+)
+
+// Hook runs loop in its own goroutine for the lifetime of the app. The loop
+// returns once stop is closed; stopping the app waits for that, or until its
+// stop timeout runs out.
+func Hook(lifecycle fx.Lifecycle, loop func(stop <-chan struct{})) {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	lifecycle.Append(
+		fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				go func() {
+					defer close(done)
+					loop(stop)
+				}()
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				close(stop)
+				select {
+				case <-done:
+				case <-ctx.Done():
+				}
+				return nil
+			},
+		})
+}
+
+// Backoff spaces out the attempts of failed work: the delay starts at Base
+// and doubles with every attempt up to Max.
+type Backoff struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// Delay is the delay before the attempt that follows the given one.
+func (b Backoff) Delay(attempts int) time.Duration {
+	delay := b.Base
+	for i := 1; i < attempts && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+// Truncate shortens s to at most max bytes, e.g. an error kept with a failed
+// attempt, without cutting a character in half.
+func Truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
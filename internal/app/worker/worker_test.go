@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	backoff := Backoff{Base: 10 * time.Second, Max: time.Hour}
+	for _, tt := range []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 10 * time.Second},
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{4, 80 * time.Second},
+		{9, 2560 * time.Second},
+		{10, time.Hour},
+		{100, time.Hour},
+	} {
+		if got := backoff.Delay(tt.attempts); got != tt.want {
+			t.Errorf("Delay(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"too long", 3, "too"},
+		{"ошибка", 3, "о"},
+		{"ошибка", 4, "ош"},
+		{"ошибка", 0, ""},
+	} {
+		if got := Truncate(tt.s, tt.max); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+}
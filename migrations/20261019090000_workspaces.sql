@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS workspaces (
+    id BIGINT NOT NULL PRIMARY KEY,
+    name TEXT NOT NULL,
+    owner_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id BIGINT NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS workspace_members_user_id_idx ON workspace_members (user_id);
+-- +goose StatementEnd
+
+-- Tenant-scoped transactions expose the active workspace and user through
+-- these settings (see repository.inWorkspace / repository.asUser). Every
+-- workspace-scoped table gets a policy comparing its workspace_id with
+-- current_workspace_id(). Policies are not enforced for superusers, so the
+-- application must connect as an ordinary role.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION current_workspace_id() RETURNS BIGINT AS $$
+    SELECT NULLIF(current_setting('app.workspace_id', true), '')::BIGINT
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION current_app_user_id() RETURNS BIGINT AS $$
+    SELECT NULLIF(current_setting('app.user_id', true), '')::BIGINT
+$$ LANGUAGE SQL STABLE;
+
+ALTER TABLE workspace_members ENABLE ROW LEVEL SECURITY;
+ALTER TABLE workspace_members FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY workspace_members_tenant ON workspace_members
+    USING (workspace_id = current_workspace_id());
+
+CREATE POLICY workspace_members_self ON workspace_members FOR SELECT
+    USING (user_id = current_app_user_id());
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+DROP FUNCTION IF EXISTS current_workspace_id();
+DROP FUNCTION IF EXISTS current_app_user_id();
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- reg_date was never written before; legacy rows get the migration time.
+UPDATE users SET reg_date = NOW() WHERE reg_date IS NULL;
+ALTER TABLE users ALTER COLUMN reg_date SET DEFAULT NOW();
+ALTER TABLE users ALTER COLUMN reg_date SET NOT NULL;
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS display_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS avatar TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC',
+    ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'ru',
+    ADD COLUMN IF NOT EXISTS bio TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS last_login TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS display_name,
+    DROP COLUMN IF EXISTS avatar,
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS locale,
+    DROP COLUMN IF EXISTS bio,
+    DROP COLUMN IF EXISTS last_login;
+
+ALTER TABLE users ALTER COLUMN reg_date DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN reg_date DROP DEFAULT;
+-- +goose StatementEnd
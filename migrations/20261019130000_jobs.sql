@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGINT NOT NULL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    key TEXT,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    run_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS jobs_due_idx ON jobs (run_at) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS jobs_status_idx ON jobs (status, updated_at DESC);
+
+-- A keyed job is scheduled at most once at a time, so every instance can
+-- schedule recurring work on startup without duplicating it.
+CREATE UNIQUE INDEX IF NOT EXISTS jobs_pending_key_idx ON jobs (key) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS jobs;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every existing password was set through the v1 API, which hashes the
+-- client-side pwd_hash. Sign-in through v2 upgrades them one by one.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS legacy_password BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE users SET legacy_password = TRUE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS legacy_password;
+-- +goose StatementEnd
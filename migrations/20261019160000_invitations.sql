@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Invitations are accepted in the transaction creating the invited user,
+-- so they live next to users rather than with the one-time tokens in Redis.
+-- They are looked up by token before any workspace is known, so they are
+-- not workspace-scoped.
+CREATE TABLE IF NOT EXISTS invitations (
+    token TEXT NOT NULL PRIMARY KEY,
+    email TEXT NOT NULL,
+    inviter_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    workspace_id BIGINT REFERENCES workspaces (id) ON DELETE CASCADE,
+    role TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS invitations;
+-- +goose StatementEnd
@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Workspaces are visible inside their own tenant-scoped transaction and, for
+-- listing, to their members.
+ALTER TABLE workspaces ENABLE ROW LEVEL SECURITY;
+ALTER TABLE workspaces FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY workspaces_tenant ON workspaces
+    USING (id = current_workspace_id());
+
+CREATE POLICY workspaces_member ON workspaces FOR SELECT
+    USING (EXISTS (
+        SELECT 1 FROM workspace_members m
+        WHERE m.workspace_id = workspaces.id AND m.user_id = current_app_user_id()
+    ));
+-- +goose StatementEnd
+
+-- Row level security does not apply to superusers and roles with BYPASSRLS,
+-- whatever FORCE says, so the application connects as task_manager while
+-- migrations keep running as the owner of the tables. The role is created
+-- without a password; give it one with
+--   ALTER ROLE task_manager WITH LOGIN PASSWORD '...';
+-- unless it already exists, e.g. from deploy/postgres/app_role.sql.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = 'task_manager') THEN
+        CREATE ROLE task_manager NOLOGIN NOSUPERUSER NOBYPASSRLS;
+    END IF;
+END
+$$;
+
+GRANT USAGE ON SCHEMA public TO task_manager;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO task_manager;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO task_manager;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+    GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO task_manager;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+    GRANT USAGE, SELECT ON SEQUENCES TO task_manager;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+    REVOKE USAGE, SELECT ON SEQUENCES FROM task_manager;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+    REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM task_manager;
+REVOKE ALL ON ALL SEQUENCES IN SCHEMA public FROM task_manager;
+REVOKE ALL ON ALL TABLES IN SCHEMA public FROM task_manager;
+REVOKE USAGE ON SCHEMA public FROM task_manager;
+
+DROP POLICY IF EXISTS workspaces_member ON workspaces;
+DROP POLICY IF EXISTS workspaces_tenant ON workspaces;
+ALTER TABLE workspaces NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE workspaces DISABLE ROW LEVEL SECURITY;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Deliveries carry the payloads of the workspace's events, so they are bound
+-- to the tenant like the webhooks. The worker drains the queue of every
+-- workspace and claims deliveries with app.delivery_worker set instead (see
+-- repository.asDeliveryWorker); everything else it does with a delivery runs
+-- in the delivery's workspace.
+-- +goose StatementBegin
+ALTER TABLE webhook_deliveries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_deliveries FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY webhook_deliveries_tenant ON webhook_deliveries
+    USING (workspace_id = current_workspace_id());
+
+CREATE POLICY webhook_deliveries_worker ON webhook_deliveries
+    USING (current_setting('app.delivery_worker', true) = 'on');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP POLICY IF EXISTS webhook_deliveries_worker ON webhook_deliveries;
+DROP POLICY IF EXISTS webhook_deliveries_tenant ON webhook_deliveries;
+ALTER TABLE webhook_deliveries NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE webhook_deliveries DISABLE ROW LEVEL SECURITY;
+-- +goose StatementEnd
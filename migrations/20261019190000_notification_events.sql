@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A notification remembers the event it was made for, so an event handled
+-- again does not notify the same user twice. Notifications from before have
+-- no event and are left alone.
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS event_id TEXT;
+ALTER TABLE notifications ADD CONSTRAINT notifications_user_id_event_id_key UNIQUE (user_id, event_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_user_id_event_id_key;
+ALTER TABLE notifications DROP COLUMN IF EXISTS event_id;
+-- +goose StatementEnd